@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/InternPulse/famtrust-backend-auth/internal/db"
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
 	"github.com/InternPulse/famtrust-backend-auth/internal/handlers"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/InternPulse/famtrust-backend-auth/internal/jwtmod"
+	"github.com/InternPulse/famtrust-backend-auth/internal/kyc"
 	"github.com/InternPulse/famtrust-backend-auth/internal/mailer"
 	"github.com/InternPulse/famtrust-backend-auth/internal/models"
+	"github.com/InternPulse/famtrust-backend-auth/internal/notify"
+	"github.com/InternPulse/famtrust-backend-auth/internal/outbox"
+	"github.com/InternPulse/famtrust-backend-auth/internal/storage"
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	_ "github.com/InternPulse/famtrust-backend-auth/docs"
@@ -29,6 +41,25 @@ type Config struct {
 // @name						Authorization
 // @in							header
 func main() {
+	rbacDryRun := flag.Bool("rbac-dry-run", false, "Print the roles/permissions manifest diff and exit without applying it")
+	previewEmail := flag.String("preview-email", "", "Print the named email template rendered with sample data and exit")
+	previewLocale := flag.String("preview-locale", emails.DefaultLocale, "Locale of the previewed email")
+	previewHTML := flag.Bool("preview-html", false, "Print the previewed email's HTML body instead of its text body")
+	flag.Parse()
+
+	if *previewEmail != "" {
+		msg, err := emails.Render(*previewEmail, *previewLocale, emails.Sample(*previewEmail))
+		if err != nil {
+			log.Fatalf("Failed to render email: %v", err)
+		}
+		body := msg.BodyText
+		if *previewHTML {
+			body = msg.BodyHTML
+		}
+		fmt.Printf("Subject: %s\nFrom: %s\n\n%s", msg.Subject, msg.From, body)
+		return
+	}
+
 	// load env vars
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Failed to load .env file: %v", err)
@@ -37,22 +68,87 @@ func main() {
 	// init jwt
 	jwtmod.JwtKey = []byte(os.Getenv("JWTKEY"))
 
+	// init profile picture URL signing key
+	handlers.PictureURLSecret = []byte(os.Getenv("PICTURE_URL_SECRET"))
+	if len(handlers.PictureURLSecret) < 32 {
+		log.Fatalf("PICTURE_URL_SECRET must be set to at least 32 bytes")
+	}
+
+	// init the public URL that links in security alerts point back to
+	publicBaseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if u, err := url.Parse(publicBaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		log.Fatalf("PUBLIC_BASE_URL must be set to this service's public URL, such as https://auth.famtrust.biz")
+	}
+
+	// init bounce and complaint webhook secret
+	handlers.EmailWebhookSecret = []byte(os.Getenv("EMAIL_WEBHOOK_SECRET"))
+
+	// init field encryption keys
+	keyring, err := fieldcrypt.NewKeyringFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load field encryption keys: %v", err)
+	}
+
 	// new postgres instance
 	postgresDB := db.NewPostgresDB()
 
+	// encrypt legacy identity numbers and re-encrypt those under retired keys
+	if err := db.EncryptIdentityNumbers(postgresDB, keyring); err != nil {
+		log.Fatalf("Failed to encrypt identity numbers: %v", err)
+	}
+
 	// new model instance
-	models := models.NewModel(postgresDB)
+	models := models.NewModel(postgresDB, db.RLSEnabled(), keyring)
+
+	// reconcile roles and permissions with the embedded manifest
+	if err := db.ReconcileRBAC(models, *rbacDryRun, os.Stdout); err != nil {
+		log.Fatalf("Failed to reconcile roles and permissions: %v", err)
+	}
+	if *rbacDryRun {
+		return
+	}
 
 	// new mailer instance
-	mailer := mailer.NewMailer()
+	emailer, err := mailer.NewMailer()
+	if err != nil {
+		log.Fatalf("Failed to set up mailer: %v", err)
+	}
+
+	// development mailers keep what they send for the dev inbox
+	inbox, _ := emailer.(interfaces.Inbox)
+	if inbox != nil && gin.Mode() == gin.ReleaseMode {
+		log.Printf("Not serving the dev inbox in release mode")
+		inbox = nil
+	}
+
+	// email security alerts about account events through the outbox
+	notifier := notify.NewNotifier(models, publicBaseURL)
+
+	// deliver queued emails in the background, except to suppressed addresses
+	go outbox.NewWorker(models, mailer.NewSuppressedMailer(emailer, models.Suppressions())).Run(context.Background())
+
+	// new profile picture store, swept of pictures no profile uses
+	store, err := storage.NewStore()
+	if err != nil {
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+	go storage.NewSweeper(models, store).Run(context.Background())
+
+	// move ID documents saved on local disk into the store
+	if err := db.MoveKYCDocuments(postgresDB, store); err != nil {
+		log.Fatalf("Failed to move ID documents into storage: %v", err)
+	}
+
+	// new identity verification provider
+	kycProvider := kyc.NewProvider()
 
 	// new app instance
 	app := Config{
-		Handlers: handlers.NewHandler(models, mailer),
+		Handlers: handlers.NewHandler(models, kycProvider, notifier, store, inbox),
 	}
 
 	// Run app
-	err := app.routes().Run(webPort)
+	err = app.routes().Run(webPort)
 	if err != nil {
 		log.Fatalf("Failed to start web api; %v", err)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -36,9 +37,14 @@ func (app *Config) routes() *gin.Engine {
 
 	// User Routes
 	Users := v1.Group("/users").Use(app.Handlers.AuthMiddleware())
-	Users.GET("/", app.Handlers.Users().GetUsersByDefaultGroup)
-	Users.POST("/", app.Handlers.Users().CreateUser)
-	Users.GET("/:userID", app.Handlers.Users().GetUserByDefaultGroup)
+	Users.GET("/", app.Handlers.RequirePermission(rbac.CanListUsers), app.Handlers.Users().GetUsersByDefaultGroup)
+	Users.POST("/", app.Handlers.RequirePermission(rbac.CanCreateUsers), app.Handlers.Users().CreateUser)
+	Users.GET("/:userID", app.Handlers.RequirePermission(rbac.CanListUsers), app.Handlers.Users().GetUserByDefaultGroup)
+	Users.GET("/:userID/profile/history", app.Handlers.RequirePermission(rbac.CanViewProfileHistory), app.Handlers.Users().GetMemberProfileHistory)
+	Users.PUT("/:userID/role", app.Handlers.RequirePermission(rbac.CanManageFamilyRoles), app.Handlers.Roles().AssignUserRole)
+	Users.GET("/:userID/grants", app.Handlers.RequirePermission(rbac.CanDelegatePermissions), app.Handlers.Roles().GetUserGrants)
+	Users.POST("/:userID/grants", app.Handlers.RequirePermission(rbac.CanDelegatePermissions), app.Handlers.Roles().CreateUserGrant)
+	Users.DELETE("/:userID/grants/:grantID", app.Handlers.RequirePermission(rbac.CanDelegatePermissions), app.Handlers.Roles().RevokeUserGrant)
 
 	// // Verification Routes
 	v1.GET("/verify-nin", app.Handlers.Verifications().VerifyNIN)
@@ -48,12 +54,84 @@ func (app *Config) routes() *gin.Engine {
 	// Protected Routes
 	v1.GET("/validate", app.Handlers.AuthMiddleware(), app.Handlers.Users().Validate)
 	v1.GET("/verify-email", app.Handlers.AuthMiddleware(), app.Handlers.Verifications().VerifyEmail)
+	v1.POST("/authorize", app.Handlers.AuthMiddleware(), app.Handlers.Authz().Authorize)
+
+	// Security Routes [Protected]
+	security := v1.Group("/security")
+	security.GET("/lockout", app.Handlers.Security().GetLockoutPage)
+	security.POST("/lockout", app.Handlers.Security().LockAccount)
+	security.PUT("/2fa", app.Handlers.AuthMiddleware(), app.Handlers.Security().SetTwoFactor)
+	security.GET("/alerts", app.Handlers.AuthMiddleware(), app.Handlers.Security().GetAlertSettings)
+	security.PUT("/alerts", app.Handlers.AuthMiddleware(), app.Handlers.Security().MuteAlerts)
+
+	// KYC Routes [Protected]
+	kycRoutes := v1.Group("/kyc").Use(app.Handlers.AuthMiddleware())
+	kycRoutes.GET("/verifications", app.Handlers.Verifications().GetKYCVerifications)
+	kycRoutes.POST("/verifications", app.Handlers.Verifications().CreateKYCVerification)
+	kycRoutes.GET("/documents", app.Handlers.Verifications().GetKYCDocuments)
+	kycRoutes.POST("/documents", app.Handlers.Verifications().UploadKYCDocument)
+
+	// KYC Review Routes [Protected, KYC Reviewers]
+	kycReviews := v1.Group("/kyc/reviews").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanReviewKYC))
+	kycReviews.GET("/", app.Handlers.Verifications().GetKYCReviewQueue)
+	kycReviews.GET("/:documentID/file", app.Handlers.Verifications().GetKYCDocumentFile)
+	kycReviews.POST("/:documentID/approve", app.Handlers.Verifications().ApproveKYCDocument)
+	kycReviews.POST("/:documentID/reject", app.Handlers.Verifications().RejectKYCDocument)
 
 	// UserProfile Routes [Protected]
 	profile := v1.Group("/profile").Use(app.Handlers.AuthMiddleware())
 	profile.GET("/", app.Handlers.Users().GetUserProfileByID)
 	profile.POST("/create", app.Handlers.Users().CreateUserProfile)
 	profile.PUT("/update", app.Handlers.Users().UpdateUserProfile)
+	profile.PATCH("/", app.Handlers.Users().PatchUserProfile)
+	profile.GET("/history", app.Handlers.Users().GetProfileHistory)
+
+	// Role Routes [Protected, Platform Admin]
+	roles := v1.Group("/roles").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanManageRoles))
+	roles.GET("/", app.Handlers.Roles().GetAllRoles)
+	roles.POST("/", app.Handlers.Roles().CreateRole)
+	roles.GET("/:roleID", app.Handlers.Roles().GetRoleByID)
+	roles.PUT("/:roleID", app.Handlers.Roles().UpdateRole)
+	roles.DELETE("/:roleID", app.Handlers.Roles().DeleteRole)
+	roles.PUT("/:roleID/parents", app.Handlers.Roles().SetRoleParents)
+	roles.POST("/:roleID/permissions", app.Handlers.Roles().AddPermissionsToRole)
+	roles.DELETE("/:roleID/permissions/:permissionID", app.Handlers.Roles().RemovePermissionFromRole)
+
+	// Permission Routes [Protected, Platform Admin]
+	permissions := v1.Group("/permissions").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanManageRoles))
+	permissions.GET("/", app.Handlers.Roles().GetAllPermissions)
+	permissions.POST("/", app.Handlers.Roles().CreatePermission)
+	permissions.PUT("/:permissionID", app.Handlers.Roles().UpdatePermission)
+	permissions.DELETE("/:permissionID", app.Handlers.Roles().DeletePermission)
+
+	// Family Role Routes [Protected]
+	familyRoles := v1.Group("/family-roles").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanManageFamilyRoles))
+	familyRoles.GET("/", app.Handlers.Roles().GetFamilyRoles)
+	familyRoles.POST("/", app.Handlers.Roles().CreateFamilyRole)
+	familyRoles.PUT("/:roleID", app.Handlers.Roles().UpdateFamilyRole)
+	familyRoles.DELETE("/:roleID", app.Handlers.Roles().DeleteFamilyRole)
+
+	// Email Outbox Routes [Protected, Platform Admin]
+	outbox := v1.Group("/outbox").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanManageEmail))
+	outbox.GET("/", app.Handlers.Outbox().GetOutboxEmails)
+	outbox.GET("/:emailID", app.Handlers.Outbox().GetOutboxEmail)
+	outbox.POST("/:emailID/retry", app.Handlers.Outbox().RetryOutboxEmail)
+
+	// Bounce and complaint webhook [Shared secret]
+	v1.POST("/webhooks/email-feedback", app.Handlers.Outbox().ReceiveEmailFeedback)
+
+	// Suppression List Routes [Protected, Platform Admin]
+	suppressions := v1.Group("/suppressions").Use(app.Handlers.AuthMiddleware(), app.Handlers.RequirePermission(rbac.CanManageEmail))
+	suppressions.GET("/", app.Handlers.Outbox().GetSuppressions)
+	suppressions.DELETE("/:email", app.Handlers.Outbox().DeleteSuppression)
+
+	// Dev Inbox Routes [Development mailers only]
+	if inbox := app.Handlers.Inbox(); inbox != nil {
+		devInbox := v1.Group("/dev/inbox")
+		devInbox.GET("/", inbox.GetInbox)
+		devInbox.GET("/:emailID", inbox.GetInboxEmail)
+		devInbox.GET("/:emailID/html", inbox.GetInboxEmailHTML)
+	}
 
 	// Set a lower memory limit for multipart forms (default is 32 MiB)
 	mux.MaxMultipartMemory = 16 << 20 // 16 MiB
@@ -15,22 +15,104 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/images/profile-pic/{imageName}": {
+        "/authorize": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Decide whether a user may perform one or more actions, optionally on a family group or sub-account. The subject is given by exactly one of a session token or a user ID; tokens from sessions revoked since, such as by a password reset, are denied; asking about another user's ID requires the canAuthorizeSubjects permission. Checks may carry context (amount, spentToday, ownerId) for policy conditions on the subject's role or grants; allowed decisions return those conditions so the caller can enforce any it couldn't supply context for. Actions held through a role or grant but above the subject's KYC tier are denied with the tier they need. Every decision is written to the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Authorize Actions for a User",
+                "operationId": "authorize",
+                "parameters": [
+                    {
+                        "description": "Subject and checks",
+                        "name": "Checks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.authorizeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.authorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/dev/inbox": {
             "get": {
-                "description": "Get User Profile Picture",
+                "description": "List the emails captured by the file or memory mailer, most recent first, with the links in each. Only available when MAILER is file or memory and GIN_MODE isn't release",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Profiles"
+                    "Development"
                 ],
-                "summary": "Get User Profile Picture",
-                "operationId": "get-profile-pic",
+                "summary": "Get Captured Emails (Development)",
+                "operationId": "dev-inbox",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/dev/inbox/{emailID}": {
+            "get": {
+                "description": "Get an email captured by the file or memory mailer, with the links in it. Only available when MAILER is file or memory and GIN_MODE isn't release",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Development"
+                ],
+                "summary": "Get a Captured Email (Development)",
+                "operationId": "dev-inbox-email",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Picture Filename",
-                        "name": "imageName",
+                        "description": "Email ID",
+                        "name": "emailID",
                         "in": "path",
                         "required": true
                     }
@@ -41,53 +123,42 @@ const docTemplate = `{
                     },
                     "404": {
                         "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Login to FamTrust (Supports 2FA by Email)",
-                "consumes": [
-                    "application/json"
-                ],
+        "/dev/inbox/{emailID}/html": {
+            "get": {
+                "description": "Show a captured email's HTML body as a page, so its links can be clicked. Only available when MAILER is file or memory and GIN_MODE isn't release",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "User-Authentication"
+                    "Development"
                 ],
-                "summary": "Login to FamTrust (Supports 2FA by Email)",
-                "operationId": "login",
+                "summary": "View a Captured Email (Development)",
+                "operationId": "dev-inbox-email-html",
                 "parameters": [
-                    {
-                        "description": "User Credentials",
-                        "name": "Credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginRequest"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "User 2FA Code",
-                        "name": "2FACode",
-                        "in": "query"
+                        "description": "Email ID",
+                        "name": "emailID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginSampleResponse200"
-                        }
+                        "description": "OK"
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
-                        }
+                    "404": {
+                        "description": "Not Found"
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -98,37 +169,78 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile": {
+        "/family-roles": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve User Profile Details",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the roles that can be given to members of the user's family group: the global family roles and the family's own custom roles - Requires the canManageFamilyRoles permission",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Profiles"
+                    "Family-Roles"
                 ],
-                "summary": "Retrieve User Profile Details",
-                "operationId": "profile",
+                "summary": "Get Family Roles",
+                "operationId": "family-roles",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.profileSampleResponse200"
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a custom role for the user's family group from the global permission catalogue, optionally restricted by policy conditions such as daily amount limits. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, and it carries the conditions the user's own role puts on those permissions - Requires the canManageFamilyRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Family-Roles"
+                ],
+                "summary": "Create a Family Role",
+                "operationId": "create-family-role",
+                "parameters": [
+                    {
+                        "description": "New family role",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                            "$ref": "#/definitions/handlers.createFamilyRoleRequest"
                         }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -139,150 +251,2104 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile/create": {
-            "post": {
+        "/family-roles/{roleID}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create New User Profile",
+                "description": "Replace a family role's permissions, and its description or conditions when given. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, and it carries the conditions the user's own role puts on those permissions - Requires the canManageFamilyRoles permission",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Profiles"
+                    "Family-Roles"
                 ],
-                "summary": "Create New User Profile",
-                "operationId": "create-profile",
+                "summary": "Update a Family Role",
+                "operationId": "update-family-role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User's first name",
-                        "name": "firstName",
-                        "in": "formData",
+                        "description": "Family role ID",
+                        "name": "roleID",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "User's last name",
-                        "name": "lastName",
-                        "in": "formData",
-                        "required": true
+                        "description": "Updated family role",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateFamilyRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a family role no member currently holds - Requires the canManageFamilyRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Family-Roles"
+                ],
+                "summary": "Delete a Family Role",
+                "operationId": "delete-family-role",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "User's biography",
-                        "name": "bio",
-                        "in": "formData",
+                        "description": "Family role ID",
+                        "name": "roleID",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
-                    {
-                        "type": "integer",
-                        "description": "User's National Identification Number",
-                        "name": "nin",
-                        "in": "formData"
+                    "400": {
+                        "description": "Bad Request"
                     },
-                    {
-                        "type": "integer",
-                        "description": "User's Bank Verification Number",
-                        "name": "bvn",
-                        "in": "formData"
+                    "403": {
+                        "description": "Forbidden"
                     },
-                    {
-                        "type": "file",
-                        "description": "User's profile picture",
-                        "name": "profilePicture",
-                        "in": "formData",
-                        "required": true
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/profile-pic/{imageName}": {
+            "get": {
+                "description": "Get User Profile Picture, or a square thumbnail of it with the size parameter. Private pictures need the signed URL (expires and sig) from the owner's profile or the family's user list; public ones don't. Users without a picture get a default avatar named avatar-\u003cuserID\u003e-\u003cinitials\u003e.png (or .svg), which is public. Responses carry an ETag and may be cached until the URL expires",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Get User Profile Picture",
+                "operationId": "get-profile-pic",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Picture Filename",
+                        "name": "imageName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Side of a square thumbnail in pixels: 64, 128 or 256",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of a signed URL, in Unix seconds",
+                        "name": "expires",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of a signed URL",
+                        "name": "sig",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/kyc/documents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every ID document the user has uploaded for review, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Get the User's ID Documents",
+                "operationId": "kyc-documents",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a photo of a government ID for a reviewer to check when automated NIN/BVN verification can't verify the user. The profile must have a date of birth. The file is stored privately and the document joins the review queue; the user is emailed the outcome.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Upload an ID Document for Manual Review",
+                "operationId": "upload-kyc-document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID being verified: nin or bvn",
+                        "name": "idType",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The 11-digit NIN or BVN",
+                        "name": "number",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ninSlip, nationalIdCard, passport, driversLicence or votersCard",
+                        "name": "documentType",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "JPEG or PNG image of the document",
+                        "name": "document",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/kyc/reviews": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List ID documents by review status, oldest first, with the applicant details to compare them against. Numbers are masked unless the reviewer has canViewIdentityNumbers - Requires the canReviewKYC permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Get the ID Document Review Queue",
+                "operationId": "kyc-review-queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending (default), approved or rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/kyc/reviews/{documentID}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve a pending ID document. The number is saved to the user's profile as verified and the user is emailed - Requires the canReviewKYC permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Approve an ID Document",
+                "operationId": "approve-kyc-document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "documentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/kyc/reviews/{documentID}/file": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the image of an uploaded ID document - Requires the canReviewKYC permission",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Download an ID Document",
+                "operationId": "kyc-document-file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "documentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/kyc/reviews/{documentID}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reject a pending ID document with a reason, which is emailed to the user - Requires the canReviewKYC permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Reject an ID Document",
+                "operationId": "reject-kyc-document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "documentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the document was rejected",
+                        "name": "Reason",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.rejectKYCDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/kyc/verifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every NIN and BVN verification the user has run, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Get the User's NIN and BVN Verifications",
+                "operationId": "kyc-verifications",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Look the user's NIN or BVN up with the identity provider and match the name and date of birth on record against their profile. The check is stored with its outcome (verified, mismatch or failed); a verified number is saved to the profile.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Verify the User's NIN or BVN",
+                "operationId": "create-kyc-verification",
+                "parameters": [
+                    {
+                        "description": "ID type and number",
+                        "name": "Verification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.kycVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway"
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Login to FamTrust (Supports 2FA by Email)",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Authentication"
+                ],
+                "summary": "Login to FamTrust (Supports 2FA by Email)",
+                "operationId": "login",
+                "parameters": [
+                    {
+                        "description": "User Credentials",
+                        "name": "Credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "User 2FA Code",
+                        "name": "2FACode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponse200"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List up to 500 emails in the outbox by status, most recent first. Dead emails failed every delivery attempt; pending emails with attempts are being retried - Requires the canManageEmail permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Get Outbox Emails",
+                "operationId": "outbox-emails",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "dead (default), pending or sent",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/{emailID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an email's delivery status, attempts and last error - Requires the canManageEmail permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Get an Outbox Email",
+                "operationId": "outbox-email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email ID",
+                        "name": "emailID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/{emailID}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue an unsent email for immediate delivery with a fresh set of attempts, typically after fixing whatever made it dead - Requires the canManageEmail permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Retry an Outbox Email",
+                "operationId": "retry-outbox-email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email ID",
+                        "name": "emailID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Permissions - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get All Permissions",
+                "operationId": "all-permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a Permission - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Create a Permission",
+                "operationId": "create-permission",
+                "parameters": [
+                    {
+                        "description": "New permission",
+                        "name": "Permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createPermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions/{permissionID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a Permission's description and minimum KYC tier - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Update a Permission",
+                "operationId": "update-permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Permission ID",
+                        "name": "permissionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated permission",
+                        "name": "Permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updatePermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a Permission and detach it from every role - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete a Permission",
+                "operationId": "delete-permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Permission ID",
+                        "name": "permissionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve User Profile Details. NIN and BVN are masked to their last four digits unless the user has canViewIdentityNumbers. The ETag header is the profile's version, to send as If-Match when patching it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Retrieve User Profile Details",
+                "operationId": "profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.profileSampleResponse200"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change some fields of the user's profile. Fields left out are unchanged; an empty string clears bio, nin, bvn or dateOfBirth. Send the ETag from the profile as If-Match to fail with 412 instead of overwriting someone else's change. The new ETag is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Patch User Profile",
+                "operationId": "patch-profile",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "Changes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.patchProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile the changes are based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.profileSampleResponse200"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create New User Profile",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Create New User Profile",
+                "operationId": "create-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User's first name",
+                        "name": "firstName",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's last name",
+                        "name": "lastName",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's biography",
+                        "name": "bio",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User's National Identification Number",
+                        "name": "nin",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User's Bank Verification Number",
+                        "name": "bvn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's date of birth (YYYY-MM-DD), needed for NIN/BVN verification",
+                        "name": "dateOfBirth",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "User's profile picture: a JPEG, PNG or WebP image",
+                        "name": "profilePicture",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "New User's default family group Name",
+                        "name": "familyGroupName",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "New User's default family group Description",
+                        "name": "familyGroupDescription",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Let anyone with the picture's URL see it; by default only the user's family can",
+                        "name": "pictureIsPublic",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/profile/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the changes made to the user's profile, newest first, 50 at a time. Each lists the fields changed with their old and new values, who changed them and when. NIN and BVN changes are marked redacted without their values. Pass nextBefore from a page as before to get the next one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Get the User's Profile History",
+                "operationId": "profile-history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only changes to versions before this one",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError401"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/update": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update User Profile. Send the fields as JSON, or as multipart/form-data to upload a new picture",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Update User Profile",
+                "operationId": "update-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User's first name",
+                        "name": "firstName",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's last name",
+                        "name": "lastName",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's biography",
+                        "name": "bio",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User's National Identification Number",
+                        "name": "nin",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User's Bank Verification Number",
+                        "name": "bvn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User's date of birth (YYYY-MM-DD), needed for NIN/BVN verification",
+                        "name": "dateOfBirth",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the user's emails: en, yo, ig, ha or pcm",
+                        "name": "locale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Let anyone with the picture's URL see it, or only the user's family",
+                        "name": "pictureIsPublic",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "User's profile picture: a JPEG, PNG or WebP image",
+                        "name": "profilePicture",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reset User Password. With email, emails the user a reset link; with the code from that link, sets the new password from the body",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Accounts"
+                ],
+                "summary": "Reset User Password",
+                "operationId": "reset-password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User Email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password reset code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "description": "New user password, with code",
+                        "name": "Password",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.resetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Roles and their permissions, leaving out family roles, which are listed through /family-roles - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get All Roles",
+                "operationId": "all-roles",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a Role with an optional list of existing permissions, parent roles to inherit permissions from and policy conditions - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Create a Role",
+                "operationId": "create-role",
+                "parameters": [
+                    {
+                        "description": "New role",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get One Role and its permissions - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get One Role",
+                "operationId": "one-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a Role's description when one is given, and replace its permissions or conditions when a list is given. Family roles are managed through /family-roles - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Update a Role",
+                "operationId": "update-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated role",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a Role no user currently holds and no other role inherits from. Family roles are managed through /family-roles - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete a Role",
+                "operationId": "delete-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleID}/parents": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the roles a Role inherits permissions from. An empty list removes all parents; changes that would make a role inherit from itself are rejected - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Set a Role's Parent Roles",
+                "operationId": "set-role-parents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parent role IDs",
+                        "name": "Parents",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.roleParentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleID}/permissions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attach existing Permissions to a Role - Requires the canManageRoles permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Attach Permissions to a Role",
+                "operationId": "attach-role-permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permission IDs to attach",
+                        "name": "Permissions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.rolePermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleID}/permissions/{permissionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Detach a Permission from a Role - Requires the canManageRoles permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Detach a Permission from a Role",
+                "operationId": "detach-role-permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Permission ID",
+                        "name": "permissionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/security/2fa": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn two-factor authentication on or off for the user's account. The user's password is required, and turning 2FA off sends a security alert",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Turn 2FA On or Off",
+                "operationId": "set-2fa",
+                "parameters": [
+                    {
+                        "description": "New 2FA setting and the user's password",
+                        "name": "Settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.twoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/security/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the account events the user is emailed about, whether each is muted, and whether it is critical. Critical alerts can't be muted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Get Security Alert Settings",
+                "operationId": "security-alerts",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the non-critical account events the user isn't emailed about, replacing the current list. Critical alerts (passwordChanged, twoFactorDisabled) can't be muted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Mute Security Alerts",
+                "operationId": "mute-security-alerts",
+                "parameters": [
+                    {
+                        "description": "Alerts to mute; an empty list unmutes all",
+                        "name": "Alerts",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.muteAlertsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/security/lockout": {
+            "get": {
+                "description": "The page the link in a security alert opens, asking the user to confirm locking their account",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Confirm Locking an Account (\"This Wasn't Me\")",
+                "operationId": "security-lockout-page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lockout code from the alert email",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "description": "Used from the link in a security alert when the user didn't do what it reports. Freezes the account, so it can't log in or be authorized for anything, and signs it out of every session; FamTrust support restores access. Form posts from the confirmation page get an HTML reply",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Lock an Account (\"This Wasn't Me\")",
+                "operationId": "security-lockout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lockout code from the alert email",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Create an Admin/Main User Account",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Accounts"
+                ],
+                "summary": "Create an Admin/Main User Account",
+                "operationId": "signup",
+                "parameters": [
+                    {
+                        "description": "The new user; has2FA is optional, and locale (en, yo, ig, ha or pcm) defaults to en",
+                        "name": "User",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.signupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppressions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the addresses no email is sent to because mail to them hard-bounced or was marked as spam, most recent first - Requires the canManageEmail permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Get Suppressed Email Addresses",
+                "operationId": "email-suppressions",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppressions/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take an address off the suppression list, once its owner has fixed their mailbox, so email is sent to it again. Dead emails to it can then be retried from the outbox - Requires the canManageEmail permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Remove a Suppressed Email Address",
+                "operationId": "delete-email-suppression",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Users in Group - Requires the canListUsers permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Accounts"
+                ],
+                "summary": "Get All Users in Group",
+                "operationId": "all-users-in-group",
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
                     },
-                    {
-                        "type": "string",
-                        "description": "New User's default family group Name",
-                        "name": "familyGroupName",
-                        "in": "formData"
+                    "403": {
+                        "description": "Forbidden"
                     },
-                    {
-                        "type": "string",
-                        "description": "New User's default family group Description",
-                        "name": "familyGroupDescription",
-                        "in": "formData"
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
                     }
-                ],
-                "responses": {}
-            }
-        },
-        "/profile/update": {
-            "put": {
+                }
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update User Profile",
+                "description": "Create a Sub-User/Member User Account - Requires the canCreateUsers permission",
                 "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded",
                     "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Profiles"
+                    "User-Accounts"
                 ],
-                "summary": "Update User Profile",
-                "operationId": "update-profile",
+                "summary": "Create a Sub-User/Member User Account",
+                "operationId": "create-user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User's first name",
-                        "name": "firstName",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "User's last name",
-                        "name": "lastName",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "User's biography",
-                        "name": "bio",
-                        "in": "formData"
+                        "description": "The new user. roleId (roleID in form data) is a global family role or one of the family's own roles, and defaults to member. has2FA is optional, and locale (en, yo, ig, ha or pcm) defaults to en",
+                        "name": "User",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
                     },
-                    {
-                        "type": "integer",
-                        "description": "User's National Identification Number",
-                        "name": "nin",
-                        "in": "formData"
+                    "400": {
+                        "description": "Bad Request"
                     },
-                    {
-                        "type": "integer",
-                        "description": "User's Bank Verification Number",
-                        "name": "bvn",
-                        "in": "formData"
+                    "403": {
+                        "description": "Forbidden"
                     },
-                    {
-                        "type": "file",
-                        "description": "User's profile picture",
-                        "name": "profilePicture",
-                        "in": "formData"
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
                     }
-                ],
-                "responses": {}
+                }
             }
         },
-        "/reset-password": {
-            "post": {
+        "/users/{userID}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Reset User Password",
+                "description": "Get One User in User's Group - Requires the canListUsers permission",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,35 +2358,27 @@ const docTemplate = `{
                 "tags": [
                     "User-Accounts"
                 ],
-                "summary": "Reset User Password",
-                "operationId": "reset-password",
+                "summary": "Get One User",
+                "operationId": "one-user-group",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User Email",
-                        "name": "email",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Password reset code",
-                        "name": "code",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "New user password",
-                        "name": "newPassword",
-                        "in": "formData"
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -330,49 +2388,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/signup": {
-            "post": {
-                "description": "Create an Admin/Main User Account",
-                "consumes": [
-                    "multipart/form-data"
+        "/users/{userID}/grants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get all temporary permission grants of a member of the user's family group, including expired and revoked ones - Requires the canDelegatePermissions permission",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Accounts"
+                    "Family-Roles"
                 ],
-                "summary": "Create an Admin/Main User Account",
-                "operationId": "signup",
+                "summary": "Get a Family Member's Permission Grants",
+                "operationId": "user-grants",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Email of the new user",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Password of the new user",
-                        "name": "password",
-                        "in": "formData",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Optional true or false value to set new user 2FA preference",
-                        "name": "has2FA",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -380,16 +2433,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get All Users in Group - Requires the canListUsers permission",
+                "description": "Give a member of the user's family group a permission between startsAt (default now) and endsAt, for at most 90 days. Only permissions the user holds through their own role or its parent roles can be delegated, and the grant carries any conditions the user's role places on the permission as well as the conditions given - Requires the canDelegatePermissions permission",
                 "consumes": [
                     "application/json"
                 ],
@@ -397,10 +2448,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User-Accounts"
+                    "Family-Roles"
+                ],
+                "summary": "Grant a Family Member a Temporary Permission",
+                "operationId": "create-user-grant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permission and time window",
+                        "name": "Grant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createGrantRequest"
+                        }
+                    }
                 ],
-                "summary": "Get All Users in Group",
-                "operationId": "all-users-in-group",
                 "responses": {
                     "201": {
                         "description": "Created"
@@ -408,6 +2477,12 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -415,60 +2490,106 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/users/{userID}/grants/{grantID}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a Sub-User/Member User Account - Requires the canCreateUsers permission",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Revoke a temporary permission grant before it ends - Requires the canDelegatePermissions permission",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User-Accounts"
+                    "Family-Roles"
                 ],
-                "summary": "Create a Sub-User/Member User Account",
-                "operationId": "create-user",
+                "summary": "Revoke a Family Member's Permission Grant",
+                "operationId": "revoke-user-grant",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Email of the new user",
-                        "name": "email",
-                        "in": "formData",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Password of the new user",
-                        "name": "password",
-                        "in": "formData",
+                        "description": "Grant ID",
+                        "name": "grantID",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/profile/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the changes made to the profile of a member of the user's family group, newest first, 50 at a time. NIN and BVN changes are marked redacted without their values. Pass nextBefore from a page as before to get the next one - Requires the canViewProfileHistory permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Profiles"
+                ],
+                "summary": "Get a Family Member's Profile History",
+                "operationId": "member-profile-history",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Optional Role ID string for new user. Defaults to 'member' if not specified",
-                        "name": "roleID",
-                        "in": "formData"
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Optional true or false value to set new user 2FA preference",
-                        "name": "has2FA",
-                        "in": "formData"
+                        "type": "integer",
+                        "description": "Only changes to versions before this one",
+                        "name": "before",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -478,14 +2599,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}": {
-            "get": {
+        "/users/{userID}/role": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get One User in User's Group - Requires the canListUsers permission",
+                "description": "Give a member of the user's family group a global family role or one of the family's own roles. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, or be less restricted than the user's own role - Requires the canManageFamilyRoles permission",
                 "consumes": [
                     "application/json"
                 ],
@@ -493,10 +2614,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User-Accounts"
+                    "Family-Roles"
                 ],
-                "summary": "Get One User",
-                "operationId": "one-user-group",
+                "summary": "Assign a Role to a Family Member",
+                "operationId": "assign-user-role",
                 "parameters": [
                     {
                         "type": "string",
@@ -504,17 +2625,29 @@ const docTemplate = `{
                         "name": "userID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Role to assign",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.assignRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
-                    "401": {
-                        "description": "Unauthorized"
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -532,7 +2665,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Validate User Login Token",
+                "description": "Validate User Login Token. The user's KYC tier is included, along with any permissions their role or grants give that the tier doesn't reach yet.",
                 "consumes": [
                     "application/json"
                 ],
@@ -568,7 +2701,7 @@ const docTemplate = `{
         },
         "/verify-bvn": {
             "get": {
-                "description": "Verify User Signup BVN - Currently this is any 10 digit positive integer not currently in use by another user.",
+                "description": "Check a signup BVN is 11 digits and not in use by another user. Use /kyc/verifications to verify it against the identity provider.",
                 "produces": [
                     "application/json"
                 ],
@@ -661,41 +2794,427 @@ const docTemplate = `{
                 }
             }
         },
-        "/verify-nin": {
-            "get": {
-                "description": "Verify User Signup NIN - Currently this is any 10 digit positive integer not currently in use by another user.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Verifications"
-                ],
-                "summary": "Verify User Signup NIN",
-                "operationId": "verify-nin",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "NIN",
-                        "name": "nin",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
+        "/verify-nin": {
+            "get": {
+                "description": "Check a signup NIN is 11 digits and not in use by another user. Use /kyc/verifications to verify it against the identity provider.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verifications"
+                ],
+                "summary": "Verify User Signup NIN",
+                "operationId": "verify-nin",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "NIN",
+                        "name": "nin",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/email-feedback": {
+            "post": {
+                "description": "Webhook for the mail provider to report bounced and complained-about emails. Send either a raw delivery status notification (RFC 3464) or abuse report (RFC 5965) email, or a JSON array of events. Hard bounces and complaints put the address on the suppression list; temporary (4.x.x) bounces are ignored. Requires the X-Webhook-Secret header to match EMAIL_WEBHOOK_SECRET",
+                "consumes": [
+                    "application/json",
+                    "message/rfc822"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Report Email Bounces and Complaints",
+                "operationId": "email-feedback-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared webhook secret",
+                        "name": "X-Webhook-Secret",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Bounce and complaint events, when not sending a raw report",
+                        "name": "Events",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.emailFeedbackRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginSampleResponseError500"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.assignRoleRequest": {
+            "type": "object",
+            "required": [
+                "roleId"
+            ],
+            "properties": {
+                "roleId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.authorizeCheck": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "CanSendtoBank"
+                },
+                "context": {
+                    "$ref": "#/definitions/handlers.authorizeContext"
+                },
+                "resource": {
+                    "$ref": "#/definitions/handlers.authorizeResource"
+                }
+            }
+        },
+        "handlers.authorizeContext": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount and SpentToday are in kobo; SpentToday excludes Amount",
+                    "type": "integer",
+                    "example": 250000
+                },
+                "ownerId": {
+                    "type": "string",
+                    "example": "d38f91b2-dc3b-4f9d-aeb4-7b95c91e9d08"
+                },
+                "spentToday": {
+                    "type": "integer",
+                    "example": 1000000
+                }
+            }
+        },
+        "handlers.authorizeDecision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "allowed": {
+                    "type": "boolean"
+                },
+                "conditions": {
+                    "description": "Conditions attached to the allowing role or grant, for the caller to enforce",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resource": {
+                    "$ref": "#/definitions/handlers.authorizeResource"
+                }
+            }
+        },
+        "handlers.authorizeRequest": {
+            "type": "object",
+            "required": [
+                "checks"
+            ],
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.authorizeCheck"
+                    }
+                },
+                "subject": {
+                    "$ref": "#/definitions/handlers.authorizeSubject"
+                }
+            }
+        },
+        "handlers.authorizeResource": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "d38f91b2-dc3b-4f9d-aeb4-7b95c91e9d08"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "group",
+                        "subAccount"
+                    ],
+                    "example": "group"
+                }
+            }
+        },
+        "handlers.authorizeResponse": {
+            "type": "object",
+            "properties": {
+                "decisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.authorizeDecision"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer"
+                },
+                "subjectId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.authorizeSubject": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createFamilyRoleRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "permissions"
+            ],
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "Teen"
+                },
+                "permissions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CanSendtoSubAcc"
+                    ]
+                }
+            }
+        },
+        "handlers.createGrantRequest": {
+            "type": "object",
+            "required": [
+                "endsAt",
+                "permissionId"
+            ],
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "endsAt": {
+                    "type": "string",
+                    "example": "2024-08-08T00:00:00Z"
+                },
+                "permissionId": {
+                    "type": "string",
+                    "example": "CanSendtoBank"
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2024-08-01T00:00:00Z"
+                }
+            }
+        },
+        "handlers.createPermissionRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "minTier": {
+                    "type": "string",
+                    "example": "bvnVerified"
+                }
+            }
+        },
+        "handlers.createRoleRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inherits": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "member"
+                    ]
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "has2FA": {
+                    "type": "boolean"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password"
+                },
+                "roleId": {
+                    "description": "RoleID defaults to member",
+                    "type": "string",
+                    "example": "member"
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "handlers.emailFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "type"
+            ],
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "smtp; 550 5.1.1 user unknown"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "5.1.1"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "bounce"
+                }
+            }
+        },
+        "handlers.kycVerificationRequest": {
+            "type": "object",
+            "required": [
+                "idType",
+                "number"
+            ],
+            "properties": {
+                "idType": {
+                    "type": "string",
+                    "enum": [
+                        "nin",
+                        "bvn"
+                    ],
+                    "example": "nin"
+                },
+                "number": {
+                    "type": "string",
+                    "example": "12345678901"
+                }
+            }
+        },
         "handlers.loginRequest": {
             "type": "object",
             "required": [
@@ -766,6 +3285,61 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.muteAlertsRequest": {
+            "type": "object",
+            "required": [
+                "muted"
+            ],
+            "properties": {
+                "muted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "newDeviceLogin"
+                    ]
+                }
+            }
+        },
+        "handlers.patchProfileRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "example": ""
+                },
+                "bvn": {
+                    "type": "string",
+                    "example": "12345678901"
+                },
+                "dateOfBirth": {
+                    "type": "string",
+                    "example": "1990-01-31"
+                },
+                "firstName": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Ada"
+                },
+                "lastName": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Obi"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "yo"
+                },
+                "nin": {
+                    "type": "string",
+                    "example": "12345678901"
+                },
+                "pictureIsPublic": {
+                    "type": "boolean"
+                }
+            }
+        },
         "handlers.profileSampleResponse200": {
             "type": "object",
             "properties": {
@@ -781,8 +3355,8 @@ const docTemplate = `{
                             "example": "The best FamTrust user of all time."
                         },
                         "bvn": {
-                            "type": "integer",
-                            "example": 35473783473
+                            "type": "string",
+                            "example": "*******3473"
                         },
                         "createdAt": {
                             "type": "string",
@@ -801,8 +3375,8 @@ const docTemplate = `{
                             "example": "Guru"
                         },
                         "nin": {
-                            "type": "integer",
-                            "example": 35473745433
+                            "type": "string",
+                            "example": "*******5433"
                         },
                         "profilePictureUrl": {
                             "type": "string",
@@ -832,6 +3406,166 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.rejectKYCDocumentRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "The document is blurred, upload a clearer photo"
+                }
+            }
+        },
+        "handlers.resetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "newPassword"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string",
+                    "example": "password"
+                }
+            }
+        },
+        "handlers.roleParentsRequest": {
+            "type": "object",
+            "required": [
+                "inherits"
+            ],
+            "properties": {
+                "inherits": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "member"
+                    ]
+                }
+            }
+        },
+        "handlers.rolePermissionsRequest": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.signupRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "has2FA": {
+                    "type": "boolean"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password"
+                }
+            }
+        },
+        "handlers.twoFactorRequest": {
+            "type": "object",
+            "required": [
+                "enabled",
+                "password"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password"
+                }
+            }
+        },
+        "handlers.updateFamilyRoleRequest": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "description": {
+                    "description": "Description and Conditions are left unchanged when omitted",
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.updatePermissionRequest": {
+            "type": "object",
+            "required": [
+                "description"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "minTier": {
+                    "description": "MinTier is left unchanged when omitted; an empty string removes it",
+                    "type": "string",
+                    "example": "bvnVerified"
+                }
+            }
+        },
+        "handlers.updateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/policy.Condition"
+                    }
+                },
+                "description": {
+                    "description": "Description, Permissions and Conditions are left unchanged when omitted",
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "handlers.validateSampleResponse200": {
             "type": "object",
             "properties": {
@@ -868,6 +3602,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "user@example.com"
                 },
+                "emailSuppressed": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "has2FA": {
                     "type": "boolean",
                     "example": true
@@ -884,12 +3622,22 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": true
                 },
+                "kyctier": {
+                    "type": "string",
+                    "example": "ninVerified"
+                },
                 "lastLogin": {
                     "type": "string",
                     "example": "2024-07-22T14:30:00Z"
                 },
                 "role": {
                     "$ref": "#/definitions/handlers.validateSampleResponseRole"
+                },
+                "withheldPermissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -911,6 +3659,41 @@ const docTemplate = `{
                     ]
                 }
             }
+        },
+        "policy.Condition": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "TimeWindow: local HH:MM times; From after Until wraps past midnight",
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "limit": {
+                    "description": "MaxAmount: Limit is in kobo, over a single transaction or a calendar day",
+                    "type": "integer",
+                    "example": 5000000
+                },
+                "period": {
+                    "type": "string",
+                    "example": "day"
+                },
+                "permission": {
+                    "type": "string",
+                    "example": "CanSendtoSubAcc"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Africa/Lagos"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "maxAmount"
+                },
+                "until": {
+                    "type": "string",
+                    "example": "21:00"
+                }
+            }
         }
     },
     "securityDefinitions": {
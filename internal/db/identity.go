@@ -0,0 +1,132 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// identityFields are the encrypted identity number columns on user_profiles
+var identityFields = []string{"nin", "bvn"}
+
+// sealedColumn is a set of <prefix>_ciphertext and <prefix>_key_id columns,
+// plus <prefix>_index if indexed, holding values sealed for field.
+type sealedColumn struct {
+	table   string
+	prefix  string
+	field   string
+	indexed bool
+}
+
+// sealedColumns are re-sealed when the active key changes
+var sealedColumns = []sealedColumn{
+	{table: "user_profiles", prefix: "nin", field: "nin", indexed: true},
+	{table: "user_profiles", prefix: "bvn", field: "bvn", indexed: true},
+	{table: "kyc_documents", prefix: "number", field: "kycDocument"},
+}
+
+// EncryptIdentityNumbers moves NINs and BVNs out of the old plaintext
+// columns into encrypted ones, then re-seals any value, on profiles or KYC
+// documents, whose key is no longer the active one so retired keys can be
+// removed.
+func EncryptIdentityNumbers(db *gorm.DB, keyring *fieldcrypt.Keyring) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := BypassRLS(tx); err != nil {
+			return err
+		}
+
+		for _, field := range identityFields {
+			if err := encryptPlaintextColumn(tx, keyring, field); err != nil {
+				return err
+			}
+		}
+		for _, column := range sealedColumns {
+			if err := rotateSealedColumn(tx, keyring, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// encryptPlaintextColumn seals the values of the legacy bigint column and
+// drops it. Leading zeros lost to the bigint are restored to 11 digits.
+func encryptPlaintextColumn(tx *gorm.DB, keyring *fieldcrypt.Keyring, field string) error {
+	if !tx.Migrator().HasColumn(&interfaces.UserProfile{}, field) {
+		return nil
+	}
+
+	var rows []struct {
+		ID    uuid.UUID
+		Value int64
+	}
+	if err := tx.Table("user_profiles").Select("id, " + field + " AS value").Where(field + " <> 0").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		sealed, err := keyring.Seal(field, fmt.Sprintf("%011d", row.Value))
+		if err != nil {
+			return err
+		}
+		if err := saveSealed(tx, sealedColumn{table: "user_profiles", prefix: field, indexed: true}, row.ID, sealed); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Migrator().DropColumn(&interfaces.UserProfile{}, field); err != nil {
+		return err
+	}
+
+	log.Printf("Encrypted %d plaintext %s value(s)", len(rows), field)
+	return nil
+}
+
+func rotateSealedColumn(tx *gorm.DB, keyring *fieldcrypt.Keyring, column sealedColumn) error {
+	var rows []struct {
+		ID         uuid.UUID
+		Ciphertext string
+		KeyID      string
+	}
+	prefix := column.prefix
+	if err := tx.Table(column.table).
+		Select("id, "+prefix+"_ciphertext AS ciphertext, "+prefix+"_key_id AS key_id").
+		Where(prefix+"_ciphertext <> '' AND "+prefix+"_key_id <> ?", keyring.ActiveKeyID()).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		value, err := keyring.Open(row.Ciphertext, row.KeyID)
+		if err != nil {
+			return fmt.Errorf("open %s.%s of %s: %w", column.table, prefix, row.ID, err)
+		}
+		sealed, err := keyring.Seal(column.field, value)
+		if err != nil {
+			return err
+		}
+		if err := saveSealed(tx, column, row.ID, sealed); err != nil {
+			return err
+		}
+	}
+
+	if len(rows) > 0 {
+		log.Printf("Re-encrypted %d %s.%s value(s) with key %s", len(rows), column.table, prefix, keyring.ActiveKeyID())
+	}
+	return nil
+}
+
+func saveSealed(tx *gorm.DB, column sealedColumn, id uuid.UUID, sealed fieldcrypt.Sealed) error {
+	values := map[string]interface{}{
+		column.prefix + "_ciphertext": sealed.Ciphertext,
+		column.prefix + "_key_id":     sealed.KeyID,
+	}
+	if column.indexed {
+		values[column.prefix+"_index"] = sealed.Index
+	}
+	return tx.Table(column.table).Where("id = ?", id).Updates(values).Error
+}
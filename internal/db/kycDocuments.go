@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/storage"
+	"gorm.io/gorm"
+)
+
+// MoveKYCDocuments copies ID documents saved on local disk, from before they
+// were kept in the blob store, into the store and deletes the local files.
+// Documents whose file is already gone are left for reviewers to reject.
+func MoveKYCDocuments(db *gorm.DB, store interfaces.BlobStore) error {
+	var documents []interfaces.KYCDocument
+	if err := db.Select("id", "storage_path", "content_type").
+		Where("storage_path NOT LIKE ?", storage.KYCDocumentPrefix+"%").
+		Find(&documents).Error; err != nil {
+		return err
+	}
+
+	moved := 0
+	for _, document := range documents {
+		key := storage.KYCDocumentPrefix + path.Base(filepath.ToSlash(document.StoragePath))
+		err := moveKYCDocument(store, document.StoragePath, key, document.ContentType)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Printf("ID document %s has no file at %s", document.ID, document.StoragePath)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := db.Model(&interfaces.KYCDocument{}).Where("id = ?", document.ID).
+			Update("storage_path", key).Error; err != nil {
+			return err
+		}
+		if err := os.Remove(document.StoragePath); err != nil {
+			log.Printf("Failed to delete moved ID document %s: %v", document.StoragePath, err)
+		}
+		moved++
+	}
+
+	if moved > 0 {
+		log.Printf("Moved %d ID document(s) into the blob store", moved)
+	}
+	return nil
+}
+
+// moveKYCDocument copies the file at name into the store under key.
+func moveKYCDocument(store interfaces.BlobStore, name, key, contentType string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return store.Put(context.Background(), key, file, info.Size(), contentType)
+}
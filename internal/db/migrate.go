@@ -12,9 +12,18 @@ func Migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&interfaces.User{},
 		&interfaces.UserProfile{},
+		&interfaces.ProfileChange{},
 		&interfaces.Role{},
 		&interfaces.Permission{},
 		&interfaces.VerCode{},
+		&interfaces.RBACManifest{},
+		&interfaces.AuditLog{},
+		&interfaces.PermissionGrant{},
+		&interfaces.KYCVerification{},
+		&interfaces.KYCDocument{},
+		&interfaces.OutboxEmail{},
+		&interfaces.SuppressedAddress{},
+		&interfaces.KnownDevice{},
 	)
 	if err != nil {
 		return err
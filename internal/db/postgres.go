@@ -30,6 +30,11 @@ func NewPostgresDB() *gorm.DB {
 	}
 	log.Println("Successfully Migrated Models.")
 
+	// create or drop the family-group row-level security policies
+	if err := ConfigureRLS(db, RLSEnabled()); err != nil {
+		log.Panicf("Unable to configure row-level security %s\n", err.Error())
+	}
+
 	return db
 }
 
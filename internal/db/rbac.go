@@ -0,0 +1,203 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+)
+
+// RBACChange is a single difference between the manifest and the database.
+type RBACChange struct {
+	Summary string
+	apply   func(models interfaces.Models) error
+}
+
+// PlanRBAC lists the changes needed to bring the database in line with the manifest.
+// Roles and permissions that exist only in the database are reported but never removed.
+func PlanRBAC(m *rbac.Manifest, models interfaces.Models) ([]RBACChange, error) {
+	var changes []RBACChange
+
+	dbPerms, err := models.Permissions().GetAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, perm := range m.Permissions {
+		perm := perm
+		idx := slices.IndexFunc(dbPerms, func(p interfaces.Permission) bool { return p.ID == perm.ID })
+		switch {
+		case idx < 0:
+			changes = append(changes, RBACChange{
+				Summary: fmt.Sprintf("+ permission %s", perm.ID),
+				apply: func(models interfaces.Models) error {
+					return models.Permissions().CreatePermission(&interfaces.Permission{ID: perm.ID, Description: perm.Description, MinTier: perm.MinTier})
+				},
+			})
+		case dbPerms[idx].Description != perm.Description || dbPerms[idx].MinTier != perm.MinTier:
+			var diff []string
+			if dbPerms[idx].Description != perm.Description {
+				diff = append(diff, fmt.Sprintf("description %q -> %q", dbPerms[idx].Description, perm.Description))
+			}
+			if dbPerms[idx].MinTier != perm.MinTier {
+				diff = append(diff, fmt.Sprintf("minTier %q -> %q", dbPerms[idx].MinTier, perm.MinTier))
+			}
+			changes = append(changes, RBACChange{
+				Summary: fmt.Sprintf("~ permission %s: %s", perm.ID, strings.Join(diff, " ")),
+				apply: func(models interfaces.Models) error {
+					return models.Permissions().UpdatePermission(&interfaces.Permission{ID: perm.ID, Description: perm.Description, MinTier: perm.MinTier})
+				},
+			})
+		}
+	}
+
+	for _, perm := range dbPerms {
+		if !slices.ContainsFunc(m.Permissions, func(p rbac.ManifestPermission) bool { return p.ID == perm.ID }) {
+			changes = append(changes, RBACChange{Summary: fmt.Sprintf("! permission %s is not in the manifest and is left unchanged", perm.ID)})
+		}
+	}
+
+	dbRoles, err := models.Roles().GetAllRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range m.Roles {
+		role := role
+		rolePerms := func() []interfaces.Permission {
+			perms := []interfaces.Permission{}
+			for _, id := range role.Permissions {
+				perms = append(perms, interfaces.Permission{ID: id})
+			}
+			return perms
+		}
+
+		idx := slices.IndexFunc(dbRoles, func(r interfaces.Role) bool { return r.ID == role.ID })
+		if idx < 0 {
+			changes = append(changes, RBACChange{
+				Summary: fmt.Sprintf("+ role %s [%s]", role.ID, strings.Join(role.Permissions, ", ")),
+				apply: func(models interfaces.Models) error {
+					return models.Roles().CreateRole(&interfaces.Role{ID: role.ID, Description: role.Description, Permissions: rolePerms()})
+				},
+			})
+			continue
+		}
+
+		var current []string
+		for _, perm := range dbRoles[idx].Permissions {
+			current = append(current, perm.ID)
+		}
+
+		var diff []string
+		for _, id := range role.Permissions {
+			if !slices.Contains(current, id) {
+				diff = append(diff, "+"+id)
+			}
+		}
+		for _, id := range current {
+			if !slices.Contains(role.Permissions, id) {
+				diff = append(diff, "-"+id)
+			}
+		}
+		if dbRoles[idx].Description != role.Description {
+			diff = append(diff, fmt.Sprintf("description %q -> %q", dbRoles[idx].Description, role.Description))
+		}
+
+		if len(diff) > 0 {
+			changes = append(changes, RBACChange{
+				Summary: fmt.Sprintf("~ role %s: %s", role.ID, strings.Join(diff, " ")),
+				apply: func(models interfaces.Models) error {
+					return models.Roles().UpdateRoleByID(&interfaces.Role{ID: role.ID, Description: role.Description, Permissions: rolePerms()})
+				},
+			})
+		}
+	}
+
+	// Parents are set once every role exists, as roles may inherit from later ones
+	for _, role := range m.Roles {
+		role := role
+		idx := slices.IndexFunc(dbRoles, func(r interfaces.Role) bool { return r.ID == role.ID })
+
+		var current []string
+		if idx >= 0 {
+			for _, parent := range dbRoles[idx].Parents {
+				current = append(current, parent.ID)
+			}
+		}
+
+		slices.Sort(current)
+		wanted := slices.Clone(role.Inherits)
+		slices.Sort(wanted)
+		if slices.Equal(current, wanted) {
+			continue
+		}
+
+		changes = append(changes, RBACChange{
+			Summary: fmt.Sprintf("~ role %s: inherits [%s] -> [%s]", role.ID, strings.Join(current, ", "), strings.Join(wanted, ", ")),
+			apply: func(models interfaces.Models) error {
+				return models.Roles().SetRoleParents(role.ID, role.Inherits)
+			},
+		})
+	}
+
+	for _, role := range dbRoles {
+		// Family roles are managed by family admins, not the manifest
+		if role.GroupID != nil {
+			continue
+		}
+		if !slices.ContainsFunc(m.Roles, func(r rbac.ManifestRole) bool { return r.ID == role.ID }) {
+			changes = append(changes, RBACChange{Summary: fmt.Sprintf("! role %s is not in the manifest and is left unchanged", role.ID)})
+		}
+	}
+
+	return changes, nil
+}
+
+// ReconcileRBAC loads the embedded manifest and applies it to the database, writing
+// the diff to out. In dry-run mode the diff is written but nothing is applied.
+func ReconcileRBAC(models interfaces.Models, dryRun bool, out io.Writer) error {
+	m, err := rbac.Load()
+	if err != nil {
+		return err
+	}
+
+	applied, err := models.Roles().GetManifestVersion()
+	if err != nil {
+		return err
+	}
+
+	// Never let an older binary roll back a newer manifest
+	if applied > m.Version {
+		log.Printf("RBAC manifest v%d is older than applied v%d, skipping reconciliation", m.Version, applied)
+		return nil
+	}
+
+	changes, err := PlanRBAC(m, models)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "RBAC manifest v%d (applied: v%d), %d difference(s)\n", m.Version, applied, len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change.Summary)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	for _, change := range changes {
+		if change.apply == nil {
+			continue
+		}
+		if err := change.apply(models); err != nil {
+			return fmt.Errorf("apply %q: %w", change.Summary, err)
+		}
+	}
+
+	return models.Roles().SetManifestVersion(m.Version)
+}
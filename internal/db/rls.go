@@ -0,0 +1,108 @@
+package db
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transaction-local settings read by the row-level security policies
+const (
+	CurrentUserSetting  = "app.current_user"
+	CurrentGroupSetting = "app.current_group"
+	BypassRLSSetting    = "app.bypass_rls"
+)
+
+// rlsTables are scoped to the user in app.current_user and the family group
+// in app.current_group
+var rlsTables = []string{"users", "user_profiles", "profile_changes"}
+
+// memberOfScope matches rows belonging to the current user or a member of
+// the current family group
+const memberOfScope = `
+		current_setting('app.bypass_rls', true) = 'on'
+		OR user_id = NULLIF(current_setting('app.current_user', true), '')::uuid
+		OR user_id IN (
+			SELECT id FROM users
+			WHERE default_group = NULLIF(current_setting('app.current_group', true), '')::uuid
+		)`
+
+var rlsPolicies = map[string]string{
+	"users": `
+		current_setting('app.bypass_rls', true) = 'on'
+		OR id = NULLIF(current_setting('app.current_user', true), '')::uuid
+		OR default_group = NULLIF(current_setting('app.current_group', true), '')::uuid`,
+	"user_profiles":   memberOfScope,
+	"profile_changes": memberOfScope,
+}
+
+// RLSEnabled reports whether POSTGRES_RLS turns on row-level security mode.
+func RLSEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("POSTGRES_RLS"))
+	return enabled
+}
+
+// ConfigureRLS creates or drops the family-group policies. With RLS on, a
+// query that sets none of app.current_user, app.current_group and
+// app.bypass_rls sees no rows.
+// The policies are forced so they also bind the table owner, but superusers
+// and roles with BYPASSRLS always skip them.
+func ConfigureRLS(db *gorm.DB, enabled bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range rlsTables {
+			if err := tx.Exec("DROP POLICY IF EXISTS family_group_isolation ON " + table).Error; err != nil {
+				return err
+			}
+
+			if !enabled {
+				if err := tx.Exec("ALTER TABLE " + table + " NO FORCE ROW LEVEL SECURITY").Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("ALTER TABLE " + table + " DISABLE ROW LEVEL SECURITY").Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			condition := rlsPolicies[table]
+			if err := tx.Exec("CREATE POLICY family_group_isolation ON " + table +
+				" USING (" + condition + ") WITH CHECK (" + condition + ")").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE " + table + " ENABLE ROW LEVEL SECURITY").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE " + table + " FORCE ROW LEVEL SECURITY").Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ScopeToGroup limits the rest of the transaction to one family group's rows.
+func ScopeToGroup(tx *gorm.DB, groupID uuid.UUID) error {
+	return tx.Exec("SELECT set_config(?, ?, true)", CurrentGroupSetting, groupID.String()).Error
+}
+
+// ScopeToUser limits the rest of the transaction to a user's own rows and
+// those of their family group. Users who haven't set up a family yet all
+// have the nil group, so they only see themselves.
+func ScopeToUser(tx *gorm.DB, userID uuid.UUID, groupID uuid.UUID) error {
+	if err := tx.Exec("SELECT set_config(?, ?, true)", CurrentUserSetting, userID.String()).Error; err != nil {
+		return err
+	}
+	if groupID == uuid.Nil {
+		return nil
+	}
+	return ScopeToGroup(tx, groupID)
+}
+
+// BypassRLS lets the rest of the transaction see every family group. It is
+// the escape hatch for platform-admin and system queries, such as looking a
+// user up by email at login, that don't act for one user or group.
+func BypassRLS(tx *gorm.DB) error {
+	return tx.Exec("SELECT set_config(?, 'on', true)", BypassRLSSetting).Error
+}
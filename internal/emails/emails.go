@@ -0,0 +1,205 @@
+// Package emails renders the service's emails from embedded templates, as
+// plain text with an HTML alternative, in the recipient's language.
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates locales
+var files embed.FS
+
+// Sender is the From address of every email
+const Sender = "FamTrust <biz@famtrust.biz>"
+
+// Email templates
+const (
+	VerifyEmail   = "verifyEmail"
+	TwoFactorCode = "twoFactorCode"
+	ResetPassword = "resetPassword"
+	KYCApproved   = "kycApproved"
+	KYCRejected   = "kycRejected"
+
+	// Security alerts, named after the account events they report
+	NewDeviceLogin    = "newDeviceLogin"
+	PasswordChanged   = "passwordChanged"
+	TwoFactorDisabled = "twoFactorDisabled"
+	RoleChanged       = "roleChanged"
+)
+
+// Templates lists every email template
+var Templates = []string{
+	VerifyEmail, TwoFactorCode, ResetPassword, KYCApproved, KYCRejected,
+	NewDeviceLogin, PasswordChanged, TwoFactorDisabled, RoleChanged,
+}
+
+// Locales
+const (
+	English = "en"
+	Yoruba  = "yo"
+	Igbo    = "ig"
+	Hausa   = "ha"
+	Pidgin  = "pcm"
+)
+
+// DefaultLocale is used for users without a supported locale
+const DefaultLocale = English
+
+// Locales lists every supported locale
+var Locales = []string{English, Yoruba, Igbo, Hausa, Pidgin}
+
+// Data fills in an email template. Each template uses only some fields.
+type Data struct {
+	Link         string
+	Code         string
+	IDType       string
+	DocumentType string
+	Reason       string
+
+	// Security alerts. Link is the "this wasn't me" link; Critical hides the
+	// note on turning the alert off.
+	Time      string
+	Device    string
+	IPAddress string
+	Role      string
+	Critical  bool
+}
+
+// view is what templates execute against: the data plus the locale's strings.
+type view struct {
+	Data
+	catalog map[string]string
+}
+
+// T returns the localised string for key, formatted with args.
+func (v view) T(key string, args ...any) string {
+	if len(args) == 0 {
+		return v.catalog[key]
+	}
+	return fmt.Sprintf(v.catalog[key], args...)
+}
+
+// DocumentName is the localised name of the data's document type.
+func (v view) DocumentName() string {
+	return v.catalog["document."+v.DocumentType]
+}
+
+var (
+	catalogs = map[string]map[string]string{}
+	html     = map[string]*htmltemplate.Template{}
+	text     = map[string]*texttemplate.Template{}
+)
+
+func init() {
+	if err := load(); err != nil {
+		panic(fmt.Sprintf("emails: %v", err))
+	}
+}
+
+// load parses the embedded templates and catalogs, and checks every locale
+// has every string English has.
+func load() error {
+	for _, locale := range Locales {
+		raw, err := fs.ReadFile(files, "locales/"+locale+".yaml")
+		if err != nil {
+			return err
+		}
+		catalog := map[string]string{}
+		if err := yaml.Unmarshal(raw, &catalog); err != nil {
+			return fmt.Errorf("parse %s catalog: %w", locale, err)
+		}
+		catalogs[locale] = catalog
+	}
+
+	for key := range catalogs[English] {
+		for _, locale := range Locales {
+			if catalogs[locale][key] == "" {
+				return fmt.Errorf("%s catalog is missing %q", locale, key)
+			}
+		}
+	}
+
+	for _, name := range Templates {
+		h, err := htmltemplate.ParseFS(files, "templates/layout.html", "templates/alert.html", "templates/"+name+".html")
+		if err != nil {
+			return err
+		}
+		t, err := texttemplate.ParseFS(files, "templates/layout.txt", "templates/alert.txt", "templates/"+name+".txt")
+		if err != nil {
+			return err
+		}
+		html[name], text[name] = h, t
+	}
+
+	return nil
+}
+
+// SupportedLocale reports whether locale has translations.
+func SupportedLocale(locale string) bool {
+	return slices.Contains(Locales, locale)
+}
+
+// Render builds the named email in locale, falling back to English for
+// unsupported locales. The caller sets the recipient.
+func Render(name, locale string, data Data) (*interfaces.EmailMsg, error) {
+	h, ok := html[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	if !SupportedLocale(locale) {
+		locale = DefaultLocale
+	}
+
+	v := view{Data: data, catalog: catalogs[locale]}
+
+	var htmlBody, textBody bytes.Buffer
+	if err := h.ExecuteTemplate(&htmlBody, "layout.html", v); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+	if err := text[name].ExecuteTemplate(&textBody, "layout.txt", v); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+
+	return &interfaces.EmailMsg{
+		Subject:  v.T(name + ".subject"),
+		From:     Sender,
+		BodyText: strings.TrimSpace(textBody.String()) + "\n",
+		BodyHTML: htmlBody.String(),
+	}, nil
+}
+
+// Sample is example data for previewing the named template.
+func Sample(name string) Data {
+	switch name {
+	case VerifyEmail:
+		return Data{Link: "https://auth.famtrust.biz/api/v1/verify-email/verify?code=3f1c9a52-8d4e-4b7a-9e61-0c2d5b8f7a14"}
+	case TwoFactorCode:
+		return Data{Code: "3f1a14"}
+	case ResetPassword:
+		return Data{Link: "https://auth.famtrust.biz/api/v1/reset-password/reset?code=3f1c9a52-8d4e-4b7a-9e61-0c2d5b8f7a14"}
+	case KYCApproved:
+		return Data{IDType: "NIN", DocumentType: interfaces.DocumentNINSlip}
+	case KYCRejected:
+		return Data{IDType: "NIN", DocumentType: interfaces.DocumentNINSlip, Reason: "The photo is too blurred to read the number"}
+	case NewDeviceLogin, PasswordChanged, TwoFactorDisabled, RoleChanged:
+		return Data{
+			Link:      "https://auth.famtrust.biz/api/v1/security/lockout?code=3f1c9a52-8d4e-4b7a-9e61-0c2d5b8f7a14",
+			Time:      "19 Oct 2026 14:30 WAT",
+			Device:    "Chrome on Android",
+			IPAddress: "102.89.34.12",
+			Role:      "member",
+			Critical:  name == PasswordChanged || name == TwoFactorDisabled,
+		}
+	}
+	return Data{}
+}
@@ -0,0 +1,193 @@
+// Package fieldcrypt encrypts individual database fields with envelope
+// encryption and derives blind indexes so encrypted values can still be
+// looked up and kept unique.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keySize = 32
+
+// Sealed is an encrypted field value with the ID of the key that wrapped
+// its data key, and its blind index.
+type Sealed struct {
+	Ciphertext string
+	KeyID      string
+	Index      string
+}
+
+// Keyring holds the key-encryption keys by ID, the ID new values are
+// sealed with, and the blind index key.
+type Keyring struct {
+	active   string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring. keys are 32-byte keys by ID; active must be
+// one of them. indexKey must stay the same for the lifetime of the data,
+// since blind indexes can't be recomputed without the plaintext.
+func NewKeyring(keys map[string][]byte, active string, indexKey []byte) (*Keyring, error) {
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", id, keySize)
+		}
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", active)
+	}
+	if len(indexKey) < keySize {
+		return nil, fmt.Errorf("blind index key must be at least %d bytes", keySize)
+	}
+
+	return &Keyring{active: active, keys: keys, indexKey: indexKey}, nil
+}
+
+// NewKeyringFromEnv reads FIELD_ENCRYPTION_KEYS ("id:base64key,..."),
+// FIELD_ENCRYPTION_KEY_ID and BLIND_INDEX_KEY (base64).
+func NewKeyringFromEnv() (*Keyring, error) {
+	keys := map[string][]byte{}
+	for _, entry := range strings.Split(os.Getenv("FIELD_ENCRYPTION_KEYS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS entries must look like id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64", id)
+		}
+		keys[id] = key
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(os.Getenv("BLIND_INDEX_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("BLIND_INDEX_KEY is not valid base64")
+	}
+
+	return NewKeyring(keys, os.Getenv("FIELD_ENCRYPTION_KEY_ID"), indexKey)
+}
+
+// ActiveKeyID is the ID of the key new values are sealed with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Seal encrypts value under a fresh data key, wraps the data key with the
+// active key, and computes the value's blind index for field.
+func (k *Keyring) Seal(field, value string) (Sealed, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, err
+	}
+
+	wrapped, err := encrypt(k.keys[k.active], dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+	ciphertext, err := encrypt(dataKey, []byte(value))
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{
+		Ciphertext: base64.StdEncoding.EncodeToString(wrapped) + "." + base64.StdEncoding.EncodeToString(ciphertext),
+		KeyID:      k.active,
+		Index:      k.BlindIndex(field, value),
+	}, nil
+}
+
+// Open decrypts a value sealed with the key keyID.
+func (k *Keyring) Open(ciphertext, keyID string) (string, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q", keyID)
+	}
+
+	wrappedPart, dataPart, ok := strings.Cut(ciphertext, ".")
+	if !ok {
+		return "", errors.New("malformed ciphertext")
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(wrappedPart)
+	if err != nil {
+		return "", errors.New("malformed ciphertext")
+	}
+	data, err := base64.StdEncoding.DecodeString(dataPart)
+	if err != nil {
+		return "", errors.New("malformed ciphertext")
+	}
+
+	dataKey, err := decrypt(key, wrapped)
+	if err != nil {
+		return "", err
+	}
+	value, err := decrypt(dataKey, data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// BlindIndex is a keyed hash of value, scoped to field so equal values in
+// different fields don't share an index.
+func (k *Keyring) BlindIndex(field, value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(field + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Mask hides all but the last four characters of value.
+func Mask(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("malformed ciphertext")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/jwtmod"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuthzHandlers struct {
+	models interfaces.Models
+}
+
+// decide evaluates a single check for a loaded subject.
+func (ah *AuthzHandlers) decide(subject *interfaces.User, sources []policy.Source, withheld map[string]rbac.KYCTier, session policy.Context, check authorizeCheck) authorizeDecision {
+	decision := authorizeDecision{
+		Action:   check.Action,
+		Resource: check.Resource,
+	}
+
+	if subject.IsFrozen {
+		decision.Reason = "subject account is frozen"
+		return decision
+	}
+
+	if minTier, ok := withheld[check.Action]; ok {
+		decision.Reason = fmt.Sprintf("%s requires KYC tier %s", check.Action, minTier)
+		return decision
+	}
+
+	ctx := session
+	ctx.Amount = check.Context.Amount
+	ctx.SpentToday = check.Context.SpentToday
+	ctx.ResourceOwner = check.Context.OwnerID
+
+	result := policy.Decide(sources, check.Action, ctx)
+	if !result.Allowed {
+		decision.Reason = result.Reason
+		return decision
+	}
+	decision.Conditions = result.Conditions
+
+	grantedBy := result.Source
+	if len(result.Deferred) > 0 {
+		grantedBy = fmt.Sprintf("%s, subject to %d condition(s) the caller must enforce", grantedBy, len(result.Deferred))
+	}
+
+	switch check.Resource.Type {
+	case "group":
+		groupID, err := uuid.Parse(check.Resource.ID)
+		if err != nil {
+			decision.Reason = "resource group ID is invalid"
+			return decision
+		}
+		if groupID != subject.DefaultGroup {
+			decision.Reason = fmt.Sprintf("group %s is not the subject's family group", groupID)
+			return decision
+		}
+		decision.Reason = fmt.Sprintf("%s granted by %s in the subject's family group", check.Action, grantedBy)
+
+	case "subAccount":
+		// Sub-accounts live in the core service, which checks ownership itself
+		decision.Reason = fmt.Sprintf("%s granted by %s; sub-account ownership is checked by the core service", check.Action, grantedBy)
+
+	default:
+		decision.Reason = fmt.Sprintf("%s granted by %s", check.Action, grantedBy)
+	}
+
+	decision.Allowed = true
+	return decision
+}
+
+// @Summary		Authorize Actions for a User
+// @Description	Decide whether a user may perform one or more actions, optionally on a family group or sub-account. The subject is given by exactly one of a session token or a user ID; tokens from sessions revoked since, such as by a password reset, are denied; asking about another user's ID requires the canAuthorizeSubjects permission. Checks may carry context (amount, spentToday, ownerId) for policy conditions on the subject's role or grants; allowed decisions return those conditions so the caller can enforce any it couldn't supply context for. Actions held through a role or grant but above the subject's KYC tier are denied with the tier they need. Every decision is written to the audit log.
+// @Tags			Authorization
+// @ID				authorize
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		401	{object}	loginSampleResponseError401
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200	{object}	authorizeResponse
+// @Param			Checks	body	authorizeRequest	true	"Subject and checks"
+// @Router			/authorize [post]
+func (ah *AuthzHandlers) Authorize(c *gin.Context) {
+	var payload authorizeRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid authorization request. Between 1 and 50 checks, each with an action, are required",
+		})
+		return
+	}
+
+	if (payload.Subject.Token == "") == (payload.Subject.UserID == "") {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Subject must have exactly one of token or userId",
+		})
+		return
+	}
+
+	CallerID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+	callerID := CallerID.(uuid.UUID)
+
+	// Work out who the decision is for. A subject that can't be resolved
+	// still gets a (deny) decision per check, so that it is audited.
+	var subjectID *uuid.UUID
+	var subjectErr string
+
+	// Only a session token says whether the subject signed in with 2FA
+	var session2FA *bool
+	var claims *jwtmod.JwtClaim
+
+	if payload.Subject.Token != "" {
+		var err error
+		claims, err = jwtmod.ParseJWT(payload.Subject.Token)
+		if err != nil {
+			subjectErr = "subject token is invalid or expired"
+		} else {
+			subjectID = &claims.ID
+			session2FA = &claims.TwoFactor
+		}
+	} else {
+		id, err := uuid.Parse(payload.Subject.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Invalid subject user ID",
+			})
+			return
+		}
+
+		if id != callerID {
+			caller, err := ah.models.Users().In(callerScope(c)).GetUserByID(callerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, loginResponse{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "error",
+					Message:    "An error occured, couldn't verify user",
+				})
+				return
+			}
+
+			if !slices.Contains(rolePermissions(ah.models, caller), string(rbac.CanAuthorizeSubjects)) {
+				abortForbidden(c, []string{string(rbac.CanAuthorizeSubjects)})
+				return
+			}
+		}
+		subjectID = &id
+	}
+
+	var subject *interfaces.User
+	if subjectID != nil {
+		// The subject's token or the caller's canAuthorizeSubjects vouches
+		// for the lookup, wherever the subject's family is
+		user, err := ah.models.Users().Unscoped().GetUserByID(*subjectID)
+		switch {
+		case err != nil:
+			subjectErr = "subject does not exist"
+		case claims != nil && sessionRevoked(claims, user):
+			subjectErr = "subject session was revoked"
+		default:
+			subject = user
+		}
+	}
+
+	var sources []policy.Source
+	var withheld map[string]rbac.KYCTier
+	var session policy.Context
+	if subject != nil {
+		held := loadHeldPermissions(ah.models, subject)
+		sources, withheld = held.Sources, held.Withheld
+		session = policy.Context{
+			Now:           time.Now(),
+			SubjectID:     subject.ID,
+			EmailVerified: subject.IsVerified,
+			Session2FA:    session2FA,
+		}
+	}
+
+	decisions := make([]authorizeDecision, 0, len(payload.Checks))
+	entries := make([]interfaces.AuditLog, 0, len(payload.Checks))
+
+	for _, check := range payload.Checks {
+		var decision authorizeDecision
+		if subject == nil {
+			decision = authorizeDecision{
+				Action:   check.Action,
+				Resource: check.Resource,
+				Reason:   subjectErr,
+			}
+		} else {
+			decision = ah.decide(subject, sources, withheld, session, check)
+		}
+
+		decisions = append(decisions, decision)
+		entries = append(entries, interfaces.AuditLog{
+			Event:        "authz.decision",
+			ActorID:      &callerID,
+			SubjectID:    subjectID,
+			Action:       decision.Action,
+			ResourceType: decision.Resource.Type,
+			ResourceID:   decision.Resource.ID,
+			Allowed:      decision.Allowed,
+			Reason:       decision.Reason,
+		})
+	}
+
+	// Decisions that can't be audited are not handed out
+	if err := ah.models.AuditLogs().CreateAuditLogs(entries); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to record authorization decisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, authorizeResponse{
+		StatusCode: http.StatusOK,
+		Status:     "success",
+		Message:    "Authorization decisions made",
+		SubjectID:  subjectID,
+		Decisions:  decisions,
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by the name clients send them under
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	v.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return emails.SupportedLocale(fl.Field().String())
+	})
+	v.RegisterValidation("identityNumber", func(fl validator.FieldLevel) bool {
+		return isIdentityNumber(fl.Field().String())
+	})
+}
+
+// validationMessage explains a failed validation tag.
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if fe.Param() == "1" {
+			return "must not be empty"
+		}
+		return "must be at least " + fe.Param() + " characters"
+	case "max":
+		return "must be at most " + fe.Param() + " characters"
+	case "datetime":
+		return "must be a date in the form YYYY-MM-DD"
+	case "locale":
+		return "must be one of " + strings.Join(emails.Locales, ", ")
+	case "identityNumber":
+		return "must be an 11-digit number"
+	default:
+		return "is invalid"
+	}
+}
+
+// bindRequest binds the request body, JSON or form data according to its
+// Content-Type, into req and validates it. It writes a 400 naming the
+// fields that failed and returns false if the body isn't valid.
+func bindRequest(c *gin.Context, req any) bool {
+	err := c.ShouldBind(req)
+	if err == nil {
+		return true
+	}
+
+	fields := map[string]string{}
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			fields[fe.Field()] = validationMessage(fe)
+		}
+	case errors.As(err, &typeErr):
+		if typeErr.Type.Kind() == reflect.Bool {
+			fields[typeErr.Field] = "must be true or false"
+		} else {
+			fields[typeErr.Field] = "must be a " + typeErr.Type.String()
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, http.ErrNotMultipart):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Invalid request body. Send JSON or form data",
+		})
+		return false
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    fmt.Sprintf("Invalid request body: %v", err),
+		})
+		return false
+	}
+
+	fieldErrors(c, fields)
+	return false
+}
+
+// fieldErrors writes a 400 saying which fields failed and why.
+func fieldErrors(c *gin.Context, fields map[string]string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"statusCode": http.StatusBadRequest,
+		"status":     "error",
+		"message":    "Some fields are invalid",
+		"errors":     fields,
+	})
+}
@@ -0,0 +1,534 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// familyRoleID namespaces a family role's ID by its group so that two
+// families can both have, say, a "teen" role.
+func familyRoleID(groupID uuid.UUID, name string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	return groupID.String() + ":" + slug
+}
+
+// missingPermissions returns the IDs in wanted that are not in held.
+func missingPermissions(held []string, wanted []string) []string {
+	var missing []string
+	for _, perm := range wanted {
+		if !slices.Contains(held, perm) {
+			missing = append(missing, perm)
+		}
+	}
+	return missing
+}
+
+// inheritedConditions returns the conditions on the principal's own role that
+// restrict any of perms, which a role they create or assign must also carry.
+func inheritedConditions(models interfaces.Models, principal *Principal, perms []string) []policy.Condition {
+	var inherited []policy.Condition
+	for _, cond := range roleConditions(models, principal.User) {
+		if slices.ContainsFunc(perms, cond.AppliesTo) {
+			inherited = append(inherited, cond)
+		}
+	}
+	return inherited
+}
+
+// withConditions appends the conditions in extra that conds doesn't already have.
+func withConditions(conds []policy.Condition, extra []policy.Condition) []policy.Condition {
+	conds = slices.Clone(conds)
+	for _, cond := range extra {
+		if !slices.Contains(conds, cond) {
+			conds = append(conds, cond)
+		}
+	}
+	return conds
+}
+
+// assignableRole loads a role the principal may give to a member of their
+// family: a global family-assignable role or one of the family's own roles,
+// granting nothing, directly or through inheritance, the principal doesn't
+// hold through their own role or without the conditions their role puts on
+// it. A non-empty reason explains why the role
+// can't be assigned.
+func assignableRole(models interfaces.Models, principal *Principal, roleID string) (*interfaces.Role, string, error) {
+	r, err := models.Roles().GetRoleByID(roleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "Role does not exist", nil
+		}
+		return nil, "", err
+	}
+
+	if r.GroupID == nil && !slices.Contains(rbac.FamilyAssignableRoles, r.ID) {
+		return nil, "Role cannot be assigned by family admins", nil
+	}
+	if r.GroupID != nil && *r.GroupID != principal.User.DefaultGroup {
+		return nil, "Role does not exist", nil
+	}
+
+	granted, err := models.Roles().GetEffectivePermissions(r.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Temporary grants can't be passed on through a role
+	if missing := missingPermissions(principal.RolePermissions, granted); len(missing) > 0 {
+		return nil, fmt.Sprintf("Role grants permissions you do not hold: %s", strings.Join(missing, ", ")), nil
+	}
+
+	conds, err := models.Roles().GetEffectiveConditions(r.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, cond := range inheritedConditions(models, principal, granted) {
+		if !slices.Contains(conds, cond) {
+			return nil, "Role is less restricted than your own role", nil
+		}
+	}
+
+	return r, "", nil
+}
+
+// familyPrincipal returns the principal loaded by RequirePermission, writing
+// an error unless they belong to a family group.
+func familyPrincipal(c *gin.Context) (*Principal, bool) {
+	principal, exists := principalFrom(c)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return nil, false
+	}
+
+	if principal.User.DefaultGroup == uuid.Nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "User does not have a default family group yet",
+		})
+		return nil, false
+	}
+
+	return principal, true
+}
+
+// familyRole loads one of the principal's family roles, writing a 404 for
+// roles that don't exist or belong to another family.
+func (rh *RoleHandlers) familyRole(c *gin.Context, principal *Principal) (*interfaces.Role, bool) {
+	r, err := rh.models.Roles().GetRoleByID(c.Param("roleID"))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving role",
+		})
+		return nil, false
+	}
+
+	if err != nil || r.GroupID == nil || *r.GroupID != principal.User.DefaultGroup {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Family role does not exist",
+		})
+		return nil, false
+	}
+
+	return r, true
+}
+
+// @Summary		Get Family Roles
+// @Description	Get the roles that can be given to members of the user's family group: the global family roles and the family's own custom roles - Requires the canManageFamilyRoles permission
+// @Tags			Family-Roles
+// @ID				family-roles
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/family-roles [get]
+func (rh *RoleHandlers) GetFamilyRoles(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	cleanRoles := []role{}
+	for _, roleID := range rbac.FamilyAssignableRoles {
+		r, err := rh.models.Roles().GetRoleByID(roleID)
+		if err != nil {
+			continue
+		}
+		cleanRoles = append(cleanRoles, cleanRole(*r))
+	}
+
+	custom, err := rh.models.Roles().GetRolesByGroup(principal.User.DefaultGroup)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving roles",
+		})
+		return
+	}
+	for _, r := range custom {
+		cleanRoles = append(cleanRoles, cleanRole(r))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Family roles retrieved successfully",
+		"roles":      cleanRoles,
+	})
+}
+
+// @Summary		Create a Family Role
+// @Description	Create a custom role for the user's family group from the global permission catalogue, optionally restricted by policy conditions such as daily amount limits. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, and it carries the conditions the user's own role puts on those permissions - Requires the canManageFamilyRoles permission
+// @Tags			Family-Roles
+// @ID				create-family-role
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		201
+// @Param			Role	body	createFamilyRoleRequest	true	"New family role"
+// @Router			/family-roles [post]
+func (rh *RoleHandlers) CreateFamilyRole(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	var payload createFamilyRoleRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid role data. A name and at least one permission are required",
+		})
+		return
+	}
+
+	roleID := familyRoleID(principal.User.DefaultGroup, payload.Name)
+	if strings.HasSuffix(roleID, ":") {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Role name must contain letters or digits",
+		})
+		return
+	}
+
+	// Only permanently held permissions can go into a role, so grants can't be re-delegated
+	if missing := missingPermissions(principal.RolePermissions, payload.Permissions); len(missing) > 0 {
+		abortForbidden(c, missing)
+		return
+	}
+
+	perms, ok := rh.lookupPermissions(c, payload.Permissions)
+	if !ok {
+		return
+	}
+
+	if !validConditions(c, payload.Conditions) {
+		return
+	}
+
+	// The role can't be less restricted than the admin's own role
+	conditions := withConditions(payload.Conditions, inheritedConditions(rh.models, principal, payload.Permissions))
+
+	groupID := principal.User.DefaultGroup
+	newRole := interfaces.Role{
+		ID:          roleID,
+		Name:        payload.Name,
+		GroupID:     &groupID,
+		Description: payload.Description,
+		Conditions:  conditions,
+		Permissions: perms,
+	}
+
+	if err := rh.models.Roles().CreateRole(&newRole); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Your family already has a role with that name",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to create role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "Family role created successfully",
+		"role":       cleanRole(newRole),
+	})
+}
+
+// @Summary		Update a Family Role
+// @Description	Replace a family role's permissions, and its description or conditions when given. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, and it carries the conditions the user's own role puts on those permissions - Requires the canManageFamilyRoles permission
+// @Tags			Family-Roles
+// @ID				update-family-role
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string					true	"Family role ID"
+// @Param			Role	body	updateFamilyRoleRequest	true	"Updated family role"
+// @Router			/family-roles/{roleID} [put]
+func (rh *RoleHandlers) UpdateFamilyRole(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	var payload updateFamilyRoleRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid role data. At least one permission is required",
+		})
+		return
+	}
+
+	existing, ok := rh.familyRole(c, principal)
+	if !ok {
+		return
+	}
+
+	// Only permanently held permissions can go into a role, so grants can't be re-delegated
+	if missing := missingPermissions(principal.RolePermissions, payload.Permissions); len(missing) > 0 {
+		abortForbidden(c, missing)
+		return
+	}
+
+	perms, ok := rh.lookupPermissions(c, payload.Permissions)
+	if !ok {
+		return
+	}
+
+	if !validConditions(c, payload.Conditions) {
+		return
+	}
+
+	if payload.Description != nil {
+		existing.Description = *payload.Description
+	}
+	existing.Permissions = perms
+	if payload.Conditions != nil {
+		existing.Conditions = payload.Conditions
+	}
+	// The role can't be less restricted than the admin's own role
+	existing.Conditions = withConditions(existing.Conditions, inheritedConditions(rh.models, principal, payload.Permissions))
+
+	if err := rh.models.Roles().UpdateRoleByID(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to update role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Family role updated successfully",
+		"role":       cleanRole(*existing),
+	})
+}
+
+// @Summary		Delete a Family Role
+// @Description	Delete a family role no member currently holds - Requires the canManageFamilyRoles permission
+// @Tags			Family-Roles
+// @ID				delete-family-role
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string	true	"Family role ID"
+// @Router			/family-roles/{roleID} [delete]
+func (rh *RoleHandlers) DeleteFamilyRole(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	existing, ok := rh.familyRole(c, principal)
+	if !ok {
+		return
+	}
+
+	holders, err := rh.models.Roles().CountUsersWithRole(existing.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while checking role holders",
+		})
+		return
+	}
+
+	if holders > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"statusCode": http.StatusConflict,
+			"status":     "error",
+			"message":    "Role is still held by family members and cannot be deleted",
+			"holders":    holders,
+		})
+		return
+	}
+
+	if err := rh.models.Roles().DeleteRoleByID(existing.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to delete role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse{
+		StatusCode: http.StatusOK,
+		Status:     "success",
+		Message:    "Family role deleted successfully",
+	})
+}
+
+// @Summary		Assign a Role to a Family Member
+// @Description	Give a member of the user's family group a global family role or one of the family's own roles. The role cannot grant permissions the user does not hold through their own role, so temporary grants cannot be passed on, or be less restricted than the user's own role - Requires the canManageFamilyRoles permission
+// @Tags			Family-Roles
+// @ID				assign-user-role
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			userID	path	string				true	"User ID"
+// @Param			Role	body	assignRoleRequest	true	"Role to assign"
+// @Router			/users/{userID}/role [put]
+func (rh *RoleHandlers) AssignUserRole(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	var payload assignRoleRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "A role ID is required",
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid user ID",
+		})
+		return
+	}
+
+	if memberID == principal.User.ID {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "You cannot change your own role",
+		})
+		return
+	}
+
+	if _, err := rh.models.Users().GetUserByDefaultGroup(memberID, principal.User.DefaultGroup); err != nil {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "User is not a member of your family group",
+		})
+		return
+	}
+
+	r, reason, err := assignableRole(rh.models, principal, payload.RoleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving role",
+		})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    reason,
+		})
+		return
+	}
+
+	if err := rh.models.Users().In(callerScope(c)).SetRoleID(memberID, r.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to assign role",
+		})
+		return
+	}
+
+	event := accountEvent(c, interfaces.EventRoleChanged, memberID)
+	event.Role = r.ID
+	if r.Name != "" {
+		event.Role = r.Name
+	}
+	rh.events.Publish(event)
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Role assigned successfully",
+		"role":       cleanRole(*r),
+	})
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxGrantDuration caps how long a temporary grant may last
+const maxGrantDuration = 90 * 24 * time.Hour
+
+func cleanGrant(g interfaces.PermissionGrant) grant {
+	return grant{
+		Id:           g.ID,
+		PermissionID: g.PermissionID,
+		StartsAt:     g.StartsAt,
+		EndsAt:       g.EndsAt,
+		GrantedBy:    g.GrantedBy,
+		RevokedAt:    g.RevokedAt,
+		Conditions:   g.Conditions,
+	}
+}
+
+// familyMember loads the user named by the userID path parameter, writing an
+// error unless they are in the principal's family group.
+func (rh *RoleHandlers) familyMember(c *gin.Context, principal *Principal) (*interfaces.User, bool) {
+	memberID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid user ID",
+		})
+		return nil, false
+	}
+
+	member, err := rh.models.Users().GetUserByDefaultGroup(memberID, principal.User.DefaultGroup)
+	if err != nil {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "User is not a member of your family group",
+		})
+		return nil, false
+	}
+
+	return member, true
+}
+
+// @Summary		Get a Family Member's Permission Grants
+// @Description	Get all temporary permission grants of a member of the user's family group, including expired and revoked ones - Requires the canDelegatePermissions permission
+// @Tags			Family-Roles
+// @ID				user-grants
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			userID	path	string	true	"User ID"
+// @Router			/users/{userID}/grants [get]
+func (rh *RoleHandlers) GetUserGrants(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	member, ok := rh.familyMember(c, principal)
+	if !ok {
+		return
+	}
+
+	grants, err := rh.models.Grants().GetGrantsByUserID(member.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving grants",
+		})
+		return
+	}
+
+	cleanGrants := []grant{}
+	for _, g := range grants {
+		cleanGrants = append(cleanGrants, cleanGrant(g))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Grants retrieved successfully",
+		"grants":     cleanGrants,
+	})
+}
+
+// @Summary		Grant a Family Member a Temporary Permission
+// @Description	Give a member of the user's family group a permission between startsAt (default now) and endsAt, for at most 90 days. Only permissions the user holds through their own role or its parent roles can be delegated, and the grant carries any conditions the user's role places on the permission as well as the conditions given - Requires the canDelegatePermissions permission
+// @Tags			Family-Roles
+// @ID				create-user-grant
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		201
+// @Param			userID	path	string				true	"User ID"
+// @Param			Grant	body	createGrantRequest	true	"Permission and time window"
+// @Router			/users/{userID}/grants [post]
+func (rh *RoleHandlers) CreateUserGrant(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	var payload createGrantRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid grant data. A permission ID and an RFC 3339 end time are required",
+		})
+		return
+	}
+
+	member, ok := rh.familyMember(c, principal)
+	if !ok {
+		return
+	}
+
+	if member.ID == principal.User.ID {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "You cannot grant permissions to yourself",
+		})
+		return
+	}
+
+	// Only permanently held permissions can be delegated, so grants can't be re-delegated
+	if missing := missingPermissions(principal.RolePermissions, []string{payload.PermissionID}); len(missing) > 0 {
+		abortForbidden(c, missing)
+		return
+	}
+
+	if !validConditions(c, payload.Conditions) {
+		return
+	}
+
+	// The grant can't be less restricted than the granter's own role
+	conditions := slices.Clone(payload.Conditions)
+	for _, cond := range roleConditions(rh.models, principal.User) {
+		if cond.AppliesTo(payload.PermissionID) {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	now := time.Now()
+	startsAt := now
+	if payload.StartsAt != nil {
+		startsAt = *payload.StartsAt
+	}
+
+	if !payload.EndsAt.After(startsAt) || !payload.EndsAt.After(now) {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Grant must end in the future and after it starts",
+		})
+		return
+	}
+
+	if payload.EndsAt.Sub(startsAt) > maxGrantDuration {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Grants cannot last longer than 90 days",
+		})
+		return
+	}
+
+	newGrant := interfaces.PermissionGrant{
+		UserID:       member.ID,
+		PermissionID: payload.PermissionID,
+		StartsAt:     startsAt,
+		EndsAt:       payload.EndsAt,
+		GrantedBy:    principal.User.ID,
+		Conditions:   conditions,
+	}
+
+	if err := rh.models.Grants().CreateGrant(&newGrant); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to create grant",
+		})
+		return
+	}
+
+	if err := rh.models.AuditLogs().CreateAuditLogs([]interfaces.AuditLog{{
+		Event:     "grant.created",
+		ActorID:   &principal.User.ID,
+		SubjectID: &member.ID,
+		Action:    newGrant.PermissionID,
+		Allowed:   true,
+		Reason:    fmt.Sprintf("from %s until %s", newGrant.StartsAt.Format(time.RFC3339), newGrant.EndsAt.Format(time.RFC3339)),
+	}}); err != nil {
+		log.Printf("Failed to audit grant %s: %v", newGrant.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "Grant created successfully",
+		"grant":      cleanGrant(newGrant),
+	})
+}
+
+// @Summary		Revoke a Family Member's Permission Grant
+// @Description	Revoke a temporary permission grant before it ends - Requires the canDelegatePermissions permission
+// @Tags			Family-Roles
+// @ID				revoke-user-grant
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			userID	path	string	true	"User ID"
+// @Param			grantID	path	string	true	"Grant ID"
+// @Router			/users/{userID}/grants/{grantID} [delete]
+func (rh *RoleHandlers) RevokeUserGrant(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	member, ok := rh.familyMember(c, principal)
+	if !ok {
+		return
+	}
+
+	grantID, err := uuid.Parse(c.Param("grantID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid grant ID",
+		})
+		return
+	}
+
+	existing, err := rh.models.Grants().GetGrantByID(grantID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving grant",
+		})
+		return
+	}
+
+	if err != nil || existing.UserID != member.ID {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Grant does not exist",
+		})
+		return
+	}
+
+	if existing.RevokedAt != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Grant has already been revoked",
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := rh.models.Grants().RevokeGrant(existing.ID, now); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to revoke grant",
+		})
+		return
+	}
+	existing.RevokedAt = &now
+
+	if err := rh.models.AuditLogs().CreateAuditLogs([]interfaces.AuditLog{{
+		Event:     "grant.revoked",
+		ActorID:   &principal.User.ID,
+		SubjectID: &member.ID,
+		Action:    existing.PermissionID,
+		Reason:    fmt.Sprintf("grant %s revoked", existing.ID),
+	}}); err != nil {
+		log.Printf("Failed to audit grant revocation %s: %v", existing.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Grant revoked successfully",
+		"grant":      cleanGrant(*existing),
+	})
+}
@@ -3,8 +3,14 @@ package handlers
 import "github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 
 type Handlers struct {
+	models        interfaces.Models
 	users         interfaces.UserHandlers
 	verifications interfaces.VerificationHandlers
+	roles         interfaces.RoleHandlers
+	authz         interfaces.AuthzHandlers
+	outbox        interfaces.OutboxHandlers
+	inbox         interfaces.InboxHandlers
+	security      interfaces.SecurityHandlers
 }
 
 func (h *Handlers) Users() interfaces.UserHandlers {
@@ -15,9 +21,41 @@ func (h *Handlers) Verifications() interfaces.VerificationHandlers {
 	return h.verifications
 }
 
-func NewHandler(models interfaces.Models, mailer interfaces.Mailer) interfaces.Handlers {
-	return &Handlers{
-		users:         &UserHandlers{models: models, mailer: mailer},
-		verifications: &VerificationHandlers{models: models, mailer: mailer},
+func (h *Handlers) Roles() interfaces.RoleHandlers {
+	return h.roles
+}
+
+func (h *Handlers) Authz() interfaces.AuthzHandlers {
+	return h.authz
+}
+
+func (h *Handlers) Outbox() interfaces.OutboxHandlers {
+	return h.outbox
+}
+
+func (h *Handlers) Security() interfaces.SecurityHandlers {
+	return h.security
+}
+
+func (h *Handlers) Inbox() interfaces.InboxHandlers {
+	return h.inbox
+}
+
+// NewHandler builds the handlers. Account events are published to events and
+// uploaded profile pictures are kept in store. inbox, if not nil, is the development mailer whose captured emails the
+// inbox handlers show.
+func NewHandler(models interfaces.Models, kyc interfaces.KYCProvider, events interfaces.AccountEvents, store interfaces.BlobStore, inbox interfaces.Inbox) interfaces.Handlers {
+	h := &Handlers{
+		models:        models,
+		users:         &UserHandlers{models: models, events: events, storage: store},
+		verifications: &VerificationHandlers{models: models, kyc: kyc, storage: store},
+		roles:         &RoleHandlers{models: models, events: events},
+		authz:         &AuthzHandlers{models: models},
+		outbox:        &OutboxHandlers{models: models},
+		security:      &SecurityHandlers{models: models, events: events},
+	}
+	if inbox != nil {
+		h.inbox = &InboxHandlers{inbox: inbox}
 	}
+	return h
 }
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// InboxHandlers show the emails captured by a development mailer. They are
+// only routed when MAILER is file or memory, outside release mode.
+type InboxHandlers struct {
+	inbox interfaces.Inbox
+}
+
+// linkPattern finds the links in a plain text body
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+type inboxEmail struct {
+	interfaces.CapturedEmail
+	Links []string `json:"links"`
+}
+
+func cleanInboxEmail(email interfaces.CapturedEmail) inboxEmail {
+	links := linkPattern.FindAllString(email.BodyText, -1)
+	if links == nil {
+		links = []string{}
+	}
+	return inboxEmail{CapturedEmail: email, Links: links}
+}
+
+// @Summary		Get Captured Emails (Development)
+// @Description	List the emails captured by the file or memory mailer, most recent first, with the links in each. Only available when MAILER is file or memory and GIN_MODE isn't release
+// @Tags			Development
+// @ID				dev-inbox
+// @Produce		json
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/dev/inbox [get]
+func (ih *InboxHandlers) GetInbox(c *gin.Context) {
+	emails, err := ih.inbox.Emails()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving emails",
+		})
+		return
+	}
+
+	cleanEmails := []inboxEmail{}
+	for _, email := range emails {
+		cleanEmails = append(cleanEmails, cleanInboxEmail(email))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Emails retrieved successfully",
+		"emails":     cleanEmails,
+	})
+}
+
+// loadInboxEmail loads the email named by the emailID path parameter,
+// writing an error if there is none.
+func (ih *InboxHandlers) loadInboxEmail(c *gin.Context) (*interfaces.CapturedEmail, bool) {
+	email, err := ih.inbox.Email(c.Param("emailID"))
+	if err != nil {
+		if errors.Is(err, interfaces.ErrEmailNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Email does not exist",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving email",
+		})
+		return nil, false
+	}
+
+	return email, true
+}
+
+// @Summary		Get a Captured Email (Development)
+// @Description	Get an email captured by the file or memory mailer, with the links in it. Only available when MAILER is file or memory and GIN_MODE isn't release
+// @Tags			Development
+// @ID				dev-inbox-email
+// @Produce		json
+// @Param			emailID	path	string	true	"Email ID"
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/dev/inbox/{emailID} [get]
+func (ih *InboxHandlers) GetInboxEmail(c *gin.Context) {
+	email, ok := ih.loadInboxEmail(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Email retrieved successfully",
+		"email":      cleanInboxEmail(*email),
+	})
+}
+
+// @Summary		View a Captured Email (Development)
+// @Description	Show a captured email's HTML body as a page, so its links can be clicked. Only available when MAILER is file or memory and GIN_MODE isn't release
+// @Tags			Development
+// @ID				dev-inbox-email-html
+// @Produce		html
+// @Param			emailID	path	string	true	"Email ID"
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/dev/inbox/{emailID}/html [get]
+func (ih *InboxHandlers) GetInboxEmailHTML(c *gin.Context) {
+	email, ok := ih.loadInboxEmail(c)
+	if !ok {
+		return
+	}
+
+	if email.BodyHTML == "" {
+		c.String(http.StatusOK, email.BodyText)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.BodyHTML))
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// kycLookupTimeout bounds how long a verification waits on the provider
+const kycLookupTimeout = 20 * time.Second
+
+func cleanKYCVerification(v interfaces.KYCVerification) kycVerification {
+	return kycVerification{
+		Id:        v.ID,
+		IDType:    v.IDType,
+		Provider:  v.Provider,
+		Status:    v.Status,
+		Reason:    v.Reason,
+		CreatedAt: v.CreatedAt,
+		CheckedAt: v.CheckedAt,
+	}
+}
+
+// kycTier works out how far the user has got through identity verification.
+// A verified email comes first; beyond that the user is at the highest check
+// they've passed, so a verified BVN counts without a verified phone. A check
+// stops counting once the profile's number, name or date of birth change.
+func kycTier(models interfaces.Models, user *interfaces.User) rbac.KYCTier {
+	if !user.IsVerified {
+		return rbac.TierNone
+	}
+
+	tier := rbac.TierEmailVerified
+	if user.PhoneVerifiedAt != nil {
+		tier = rbac.TierPhoneVerified
+	}
+
+	scope := interfaces.Scope{UserID: user.ID, GroupID: user.DefaultGroup}
+	profile, err := models.Users().In(scope).GetUserProfileByID(user.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Unable to load profile of user %s for KYC tier: %v", user.ID, err)
+		}
+		return tier
+	}
+
+	verifications, err := models.KYC().GetCurrentVerifications(profile)
+	if err != nil {
+		log.Printf("Unable to load KYC verifications for user %s: %v", user.ID, err)
+	}
+	for _, v := range verifications {
+		switch v.IDType {
+		case interfaces.KYCTypeBVN:
+			tier = max(tier, rbac.TierBVNVerified)
+		case interfaces.KYCTypeNIN:
+			tier = max(tier, rbac.TierNINVerified)
+		}
+	}
+	return tier
+}
+
+// withheldPermissions returns the minimum tier of each of perms that tier
+// doesn't reach. If the minimums can't be loaded every permission is
+// withheld until the highest tier, rather than risk handing one out.
+func withheldPermissions(models interfaces.Models, tier rbac.KYCTier, perms []string) map[string]rbac.KYCTier {
+	withheld := map[string]rbac.KYCTier{}
+	if len(perms) == 0 || tier == rbac.TierBVNVerified {
+		return withheld
+	}
+
+	list, err := models.Permissions().GetPermissionsByIDs(perms)
+	if err != nil {
+		log.Printf("Unable to load KYC tiers of permissions: %v", err)
+		for _, perm := range perms {
+			withheld[perm] = rbac.TierBVNVerified
+		}
+		return withheld
+	}
+
+	for _, perm := range list {
+		minTier, err := rbac.ParseKYCTier(perm.MinTier)
+		if err != nil {
+			log.Printf("Permission %s: %v", perm.ID, err)
+			minTier = rbac.TierBVNVerified
+		}
+		if minTier > tier {
+			withheld[perm.ID] = minTier
+		}
+	}
+	return withheld
+}
+
+// isIdentityNumber reports whether s looks like an NIN or BVN: 11 digits.
+func isIdentityNumber(s string) bool {
+	if len(s) != 11 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normaliseName lowercases a name and collapses its whitespace for comparison.
+func normaliseName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// compareIdentity lists the profile details that don't match the provider's record.
+func compareIdentity(profile *interfaces.UserProfile, identity *interfaces.KYCIdentity) []string {
+	var mismatched []string
+	if normaliseName(profile.FirstName) != normaliseName(identity.FirstName) {
+		mismatched = append(mismatched, "first name")
+	}
+	if normaliseName(profile.LastName) != normaliseName(identity.LastName) {
+		mismatched = append(mismatched, "last name")
+	}
+	if profile.DateOfBirth.Format(time.DateOnly) != identity.DateOfBirth.Format(time.DateOnly) {
+		mismatched = append(mismatched, "date of birth")
+	}
+	return mismatched
+}
+
+// @Summary		Verify the User's NIN or BVN
+// @Description	Look the user's NIN or BVN up with the identity provider and match the name and date of birth on record against their profile. The check is stored with its outcome (verified, mismatch or failed); a verified number is saved to the profile.
+// @Tags			Verifications
+// @ID				create-kyc-verification
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Failure		502
+// @Success		200
+// @Param			Verification	body	kycVerificationRequest	true	"ID type and number"
+// @Router			/kyc/verifications [post]
+func (v *VerificationHandlers) CreateKYCVerification(c *gin.Context) {
+	var payload kycVerificationRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid verification data. idType must be nin or bvn, and number must be 11 digits",
+		})
+		return
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+	userID := UserID.(uuid.UUID)
+
+	profile, err := v.models.Users().In(callerScope(c)).GetUserProfileByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "User doesn't have a profile",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving profile",
+		})
+		return
+	}
+
+	if profile.DateOfBirth == nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Add your date of birth to your profile before verifying",
+		})
+		return
+	}
+
+	lookup := v.models.Users().GetUserByNIN
+	if payload.IDType == interfaces.KYCTypeBVN {
+		lookup = v.models.Users().GetUserByBVN
+	}
+
+	holder, err := lookup(payload.Number)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while checking the number",
+		})
+		return
+	}
+	if err == nil && holder.ID != userID {
+		c.JSON(http.StatusConflict, loginResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "error",
+			Message:    fmt.Sprintf("A user with this %s already exists", strings.ToUpper(payload.IDType)),
+		})
+		return
+	}
+
+	verification := interfaces.KYCVerification{
+		UserID:   userID,
+		IDType:   payload.IDType,
+		Provider: v.kyc.Name(),
+		Status:   interfaces.KYCPending,
+	}
+	if err := v.models.KYC().CreateVerification(&verification); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to start verification",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), kycLookupTimeout)
+	defer cancel()
+
+	identity, err := v.kyc.Lookup(ctx, &interfaces.KYCRequest{
+		IDType:      payload.IDType,
+		Number:      payload.Number,
+		FirstName:   profile.FirstName,
+		LastName:    profile.LastName,
+		DateOfBirth: *profile.DateOfBirth,
+	})
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, interfaces.ErrIdentityNotFound):
+		verification.Status = interfaces.KYCFailed
+		verification.Reason = err.Error()
+
+	case err != nil:
+		log.Printf("KYC lookup %s failed: %v", verification.ID, err)
+		verification.Status = interfaces.KYCFailed
+		verification.Reason = "identity provider could not be reached, try again later"
+		status = http.StatusBadGateway
+
+	default:
+		if mismatched := compareIdentity(profile, identity); len(mismatched) > 0 {
+			verification.Status = interfaces.KYCMismatch
+			verification.Reason = "profile does not match the identity on record: " + strings.Join(mismatched, ", ")
+		} else {
+			verification.Status = interfaces.KYCVerified
+			v.models.KYC().PinVerification(&verification, payload.Number, profile)
+		}
+	}
+
+	checkedAt := time.Now()
+	verification.CheckedAt = &checkedAt
+
+	if verification.Status == interfaces.KYCVerified {
+		update := interfaces.UserProfile{UserID: userID}
+		if payload.IDType == interfaces.KYCTypeBVN {
+			update.BVN = payload.Number
+		} else {
+			update.NIN = payload.Number
+		}
+		if err := v.models.Users().In(callerScope(c)).UpdateUserProfile(&update, userID); err != nil {
+			log.Printf("Failed to save verified %s for user %s: %v", payload.IDType, userID, err)
+			verification.Status = interfaces.KYCFailed
+			verification.Reason = "verified number could not be saved, try again later"
+			status = http.StatusInternalServerError
+		}
+	}
+
+	if err := v.models.KYC().UpdateVerification(&verification); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to record verification result",
+		})
+		return
+	}
+
+	outcome := "success"
+	if status != http.StatusOK {
+		outcome = "error"
+	}
+
+	c.JSON(status, gin.H{
+		"statusCode":   status,
+		"status":       outcome,
+		"message":      fmt.Sprintf("%s verification %s", strings.ToUpper(payload.IDType), verification.Status),
+		"verification": cleanKYCVerification(verification),
+	})
+}
+
+// @Summary		Get the User's NIN and BVN Verifications
+// @Description	Get every NIN and BVN verification the user has run, newest first
+// @Tags			Verifications
+// @ID				kyc-verifications
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/kyc/verifications [get]
+func (v *VerificationHandlers) GetKYCVerifications(c *gin.Context) {
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	verifications, err := v.models.KYC().GetVerificationsByUserID(UserID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving verifications",
+		})
+		return
+	}
+
+	cleanVerifications := []kycVerification{}
+	for _, verification := range verifications {
+		cleanVerifications = append(cleanVerifications, cleanKYCVerification(verification))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode":    http.StatusOK,
+		"status":        "success",
+		"message":       "Verifications retrieved successfully",
+		"verifications": cleanVerifications,
+	})
+}
@@ -0,0 +1,712 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/InternPulse/famtrust-backend-auth/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// kycReviewProvider is the provider name on verifications decided by a reviewer
+const kycReviewProvider = "manualReview"
+
+// kycDocumentTypes are the government IDs accepted for review
+var kycDocumentTypes = []string{
+	interfaces.DocumentNINSlip,
+	interfaces.DocumentNationalID,
+	interfaces.DocumentPassport,
+	interfaces.DocumentDriversLicence,
+	interfaces.DocumentVotersCard,
+}
+
+// kycDocumentExtensions maps the accepted content types to file extensions
+var kycDocumentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// cleanKYCDocument converts a document for a response, masking its number
+// unless showNumber is set.
+func cleanKYCDocument(d interfaces.KYCDocument, showNumber bool) kycDocument {
+	number := d.Number
+	if !showNumber {
+		number = fieldcrypt.Mask(number)
+	}
+
+	return kycDocument{
+		Id:           d.ID,
+		UserID:       d.UserID,
+		IDType:       d.IDType,
+		DocumentType: d.DocumentType,
+		Number:       number,
+		ContentType:  d.ContentType,
+		Status:       d.Status,
+		Reason:       d.Reason,
+		ReviewedBy:   d.ReviewedBy,
+		ReviewedAt:   d.ReviewedAt,
+		CreatedAt:    d.CreatedAt,
+	}
+}
+
+// sendKYCOutcome queues an email telling the user the result of their
+// document review. The decision is already stored, so a failure is only logged.
+func (v *VerificationHandlers) sendKYCOutcome(user *interfaces.User, document *interfaces.KYCDocument) {
+	template := emails.KYCApproved
+	if document.Status == interfaces.DocumentRejected {
+		template = emails.KYCRejected
+	}
+
+	outcome, err := emails.Render(template, user.Locale, emails.Data{
+		IDType:       strings.ToUpper(document.IDType),
+		DocumentType: document.DocumentType,
+		Reason:       document.Reason,
+	})
+	if err == nil {
+		outcome.To = user.Email
+		err = v.models.Outbox().CreateEmail(outcome)
+	}
+	if err != nil {
+		log.Printf("Failed to queue KYC review outcome email for document %s: %v", document.ID, err)
+	}
+}
+
+// @Summary		Upload an ID Document for Manual Review
+// @Description	Upload a photo of a government ID for a reviewer to check when automated NIN/BVN verification can't verify the user. The profile must have a date of birth. The file is stored privately and the document joins the review queue; the user is emailed the outcome.
+// @Tags			Verifications
+// @ID				upload-kyc-document
+// @Security		BearerAuth
+// @Accept			multipart/form-data
+// @Produce		json
+// @Param			idType			formData	string	true	"ID being verified: nin or bvn"
+// @Param			number			formData	string	true	"The 11-digit NIN or BVN"
+// @Param			documentType	formData	string	true	"ninSlip, nationalIdCard, passport, driversLicence or votersCard"
+// @Param			document		formData	file	true	"JPEG or PNG image of the document"
+// @Failure		400
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		201
+// @Router			/kyc/documents [post]
+func (v *VerificationHandlers) UploadKYCDocument(c *gin.Context) {
+	if !strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data") {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Documents must be uploaded as multipart/form-data",
+		})
+		return
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+	userID := UserID.(uuid.UUID)
+
+	idType := c.PostForm("idType")
+	number := c.PostForm("number")
+	documentType := c.PostForm("documentType")
+
+	if idType != interfaces.KYCTypeNIN && idType != interfaces.KYCTypeBVN {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "idType must be nin or bvn",
+		})
+		return
+	}
+	if !isIdentityNumber(number) {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    fmt.Sprintf("Invalid %s, it must be 11 digits", strings.ToUpper(idType)),
+		})
+		return
+	}
+	if !slices.Contains(kycDocumentTypes, documentType) {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "documentType must be one of " + strings.Join(kycDocumentTypes, ", "),
+		})
+		return
+	}
+
+	// Reviewers compare the document against the profile
+	profile, err := v.models.Users().In(callerScope(c)).GetUserProfileByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "User doesn't have a profile",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving profile",
+		})
+		return
+	}
+	if profile.DateOfBirth == nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Add your date of birth to your profile before verifying",
+		})
+		return
+	}
+
+	documents, err := v.models.KYC().GetDocumentsByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving documents",
+		})
+		return
+	}
+	if slices.ContainsFunc(documents, func(d interfaces.KYCDocument) bool {
+		return d.IDType == idType && d.Status == interfaces.DocumentPending
+	}) {
+		c.JSON(http.StatusConflict, loginResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "error",
+			Message:    fmt.Sprintf("A %s document is already waiting for review", strings.ToUpper(idType)),
+		})
+		return
+	}
+
+	lookup := v.models.Users().GetUserByNIN
+	if idType == interfaces.KYCTypeBVN {
+		lookup = v.models.Users().GetUserByBVN
+	}
+	holder, err := lookup(number)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while checking the number",
+		})
+		return
+	}
+	if err == nil && holder.ID != userID {
+		c.JSON(http.StatusConflict, loginResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "error",
+			Message:    fmt.Sprintf("A user with this %s already exists", strings.ToUpper(idType)),
+		})
+		return
+	}
+
+	file, err := c.FormFile("document")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Error parsing document. Check your upload",
+		})
+		return
+	}
+
+	const maxUploadSize = 16 << 20
+	if file.Size > maxUploadSize {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Document file is too large",
+		})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Error parsing document. Check your upload",
+		})
+		return
+	}
+	defer src.Close()
+
+	// Sniff the type rather than believe the part's Content-Type header
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Error parsing document. Check your upload",
+		})
+		return
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := kycDocumentExtensions[contentType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Document must be a JPEG or PNG image",
+		})
+		return
+	}
+
+	document := interfaces.KYCDocument{
+		UserID:       userID,
+		IDType:       idType,
+		DocumentType: documentType,
+		Number:       number,
+		ContentType:  contentType,
+		Status:       interfaces.DocumentPending,
+		StoragePath:  storage.KYCDocumentPrefix + uuid.New().String() + ext,
+	}
+
+	if err := v.storage.Put(c.Request.Context(), document.StoragePath, src, file.Size, contentType); err != nil {
+		log.Printf("Failed to store ID document %s: %v", document.StoragePath, err)
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "Failed to save document",
+		})
+		return
+	}
+
+	if err := v.models.KYC().CreateDocument(&document); err != nil {
+		if err := v.storage.Delete(c.Request.Context(), document.StoragePath); err != nil {
+			log.Printf("Failed to delete unqueued ID document %s: %v", document.StoragePath, err)
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to queue document for review",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "Document uploaded and waiting for review",
+		"document":   cleanKYCDocument(document, false),
+	})
+}
+
+// @Summary		Get the User's ID Documents
+// @Description	Get every ID document the user has uploaded for review, newest first
+// @Tags			Verifications
+// @ID				kyc-documents
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/kyc/documents [get]
+func (v *VerificationHandlers) GetKYCDocuments(c *gin.Context) {
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	documents, err := v.models.KYC().GetDocumentsByUserID(UserID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving documents",
+		})
+		return
+	}
+
+	cleanDocuments := []kycDocument{}
+	for _, document := range documents {
+		cleanDocuments = append(cleanDocuments, cleanKYCDocument(document, false))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Documents retrieved successfully",
+		"documents":  cleanDocuments,
+	})
+}
+
+// @Summary		Get the ID Document Review Queue
+// @Description	List ID documents by review status, oldest first, with the applicant details to compare them against. Numbers are masked unless the reviewer has canViewIdentityNumbers - Requires the canReviewKYC permission
+// @Tags			Verifications
+// @ID				kyc-review-queue
+// @Security		BearerAuth
+// @Produce		json
+// @Param			status	query	string	false	"pending (default), approved or rejected"
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/kyc/reviews [get]
+func (v *VerificationHandlers) GetKYCReviewQueue(c *gin.Context) {
+	principal, ok := principalFrom(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	status := c.DefaultQuery("status", interfaces.DocumentPending)
+	if !slices.Contains([]string{interfaces.DocumentPending, interfaces.DocumentApproved, interfaces.DocumentRejected}, status) {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "status must be pending, approved or rejected",
+		})
+		return
+	}
+
+	documents, err := v.models.KYC().GetDocumentsByStatus(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving documents",
+		})
+		return
+	}
+
+	showNumbers := principal.Can(rbac.CanViewIdentityNumbers)
+	cleanDocuments := []kycDocument{}
+	for _, document := range documents {
+		clean := cleanKYCDocument(document, showNumbers)
+
+		// Reviewers work across every family, so the queue isn't scoped to
+		// their own
+		user, err := v.models.Users().Unscoped().GetUserByID(document.UserID)
+		if err != nil {
+			log.Printf("Unable to load applicant %s for document %s: %v", document.UserID, document.ID, err)
+			cleanDocuments = append(cleanDocuments, clean)
+			continue
+		}
+		clean.Applicant = &kycApplicant{Email: user.Email}
+		if profile, err := v.models.Users().Unscoped().GetUserProfileByID(document.UserID); err == nil {
+			clean.Applicant.FirstName = profile.FirstName
+			clean.Applicant.LastName = profile.LastName
+			clean.Applicant.DateOfBirth = profile.DateOfBirth
+		}
+
+		cleanDocuments = append(cleanDocuments, clean)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Documents retrieved successfully",
+		"documents":  cleanDocuments,
+	})
+}
+
+// loadReviewDocument loads the document named in the path for a reviewer,
+// writing an error response if it can't be reviewed.
+func (v *VerificationHandlers) loadReviewDocument(c *gin.Context) (*interfaces.KYCDocument, bool) {
+	documentID, err := uuid.Parse(c.Param("documentID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid document ID",
+		})
+		return nil, false
+	}
+
+	document, err := v.models.KYC().GetDocumentByID(documentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Document does not exist",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving document",
+		})
+		return nil, false
+	}
+
+	return document, true
+}
+
+// @Summary		Download an ID Document
+// @Description	Download the image of an uploaded ID document - Requires the canReviewKYC permission
+// @Tags			Verifications
+// @ID				kyc-document-file
+// @Security		BearerAuth
+// @Produce		image/jpeg,image/png
+// @Param			documentID	path	string	true	"Document ID"
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Success		200
+// @Router			/kyc/reviews/{documentID}/file [get]
+func (v *VerificationHandlers) GetKYCDocumentFile(c *gin.Context) {
+	document, ok := v.loadReviewDocument(c)
+	if !ok {
+		return
+	}
+
+	file, info, err := v.storage.Get(c.Request.Context(), document.StoragePath)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Document file does not exist",
+			})
+			return
+		}
+		log.Printf("Failed to read ID document %s: %v", document.StoragePath, err)
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving the document file",
+		})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, document.ContentType, file, map[string]string{
+		"Cache-Control":          "no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// reviewKYCDocument stores a reviewer's decision on a pending document,
+// records it as a KYC verification and emails the user.
+func (v *VerificationHandlers) reviewKYCDocument(c *gin.Context, approve bool, reason string) {
+	principal, ok := principalFrom(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	document, ok := v.loadReviewDocument(c)
+	if !ok {
+		return
+	}
+
+	if document.Status != interfaces.DocumentPending {
+		c.JSON(http.StatusConflict, loginResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "error",
+			Message:    fmt.Sprintf("Document has already been %s", document.Status),
+		})
+		return
+	}
+	if document.UserID == principal.User.ID {
+		c.JSON(http.StatusForbidden, loginResponse{
+			StatusCode: http.StatusForbidden,
+			Status:     "error",
+			Message:    "You can't review your own document",
+		})
+		return
+	}
+
+	user, err := v.models.Users().Unscoped().GetUserByID(document.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving the document's owner",
+		})
+		return
+	}
+
+	now := time.Now()
+	verification := interfaces.KYCVerification{
+		UserID:    document.UserID,
+		IDType:    document.IDType,
+		Provider:  kycReviewProvider,
+		Status:    interfaces.KYCVerified,
+		CheckedAt: &now,
+	}
+
+	if approve {
+		lookup := v.models.Users().GetUserByNIN
+		if document.IDType == interfaces.KYCTypeBVN {
+			lookup = v.models.Users().GetUserByBVN
+		}
+		holder, err := lookup(document.Number)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured while checking the number",
+			})
+			return
+		}
+		if err == nil && holder.ID != document.UserID {
+			c.JSON(http.StatusConflict, loginResponse{
+				StatusCode: http.StatusConflict,
+				Status:     "error",
+				Message:    fmt.Sprintf("Another user has since verified this %s, reject the document instead", strings.ToUpper(document.IDType)),
+			})
+			return
+		}
+
+		// Pin the verification to the profile the reviewer compared the
+		// document with
+		profile, err := v.models.Users().Unscoped().GetUserProfileByID(document.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured while retrieving the document owner's profile",
+			})
+			return
+		}
+		v.models.KYC().PinVerification(&verification, document.Number, profile)
+		// Without a date of birth the verification can't count towards a tier
+		if verification.ProfileIndex == "" {
+			c.JSON(http.StatusConflict, loginResponse{
+				StatusCode: http.StatusConflict,
+				Status:     "error",
+				Message:    "The user has since removed their date of birth, reject the document instead",
+			})
+			return
+		}
+
+		update := interfaces.UserProfile{UserID: document.UserID}
+		if document.IDType == interfaces.KYCTypeBVN {
+			update.BVN = document.Number
+		} else {
+			update.NIN = document.Number
+		}
+		if err := v.models.Users().Unscoped().UpdateUserProfile(&update, principal.User.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured, failed to save the verified number",
+			})
+			return
+		}
+
+		document.Status = interfaces.DocumentApproved
+	} else {
+		document.Status = interfaces.DocumentRejected
+		document.Reason = reason
+		verification.Status = interfaces.KYCFailed
+		verification.Reason = "document rejected: " + reason
+	}
+
+	document.ReviewedBy = &principal.User.ID
+	document.ReviewedAt = &now
+	if err := v.models.KYC().ReviewDocument(document); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, loginResponse{
+				StatusCode: http.StatusConflict,
+				Status:     "error",
+				Message:    "Document has already been reviewed",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to record review",
+		})
+		return
+	}
+
+	if err := v.models.KYC().CreateVerification(&verification); err != nil {
+		log.Printf("Failed to record KYC verification for reviewed document %s: %v", document.ID, err)
+	}
+
+	v.sendKYCOutcome(user, document)
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    fmt.Sprintf("Document %s", document.Status),
+		"document":   cleanKYCDocument(*document, principal.Can(rbac.CanViewIdentityNumbers)),
+	})
+}
+
+// @Summary		Approve an ID Document
+// @Description	Approve a pending ID document. The number is saved to the user's profile as verified and the user is emailed - Requires the canReviewKYC permission
+// @Tags			Verifications
+// @ID				approve-kyc-document
+// @Security		BearerAuth
+// @Produce		json
+// @Param			documentID	path	string	true	"Document ID"
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/kyc/reviews/{documentID}/approve [post]
+func (v *VerificationHandlers) ApproveKYCDocument(c *gin.Context) {
+	v.reviewKYCDocument(c, true, "")
+}
+
+// @Summary		Reject an ID Document
+// @Description	Reject a pending ID document with a reason, which is emailed to the user - Requires the canReviewKYC permission
+// @Tags			Verifications
+// @ID				reject-kyc-document
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			documentID	path	string						true	"Document ID"
+// @Param			Reason		body	rejectKYCDocumentRequest	true	"Why the document was rejected"
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/kyc/reviews/{documentID}/reject [post]
+func (v *VerificationHandlers) RejectKYCDocument(c *gin.Context) {
+	var payload rejectKYCDocumentRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil || strings.TrimSpace(payload.Reason) == "" {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "A reason of up to 500 characters is required",
+		})
+		return
+	}
+
+	v.reviewKYCDocument(c, false, strings.TrimSpace(payload.Reason))
+}
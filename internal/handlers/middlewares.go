@@ -3,10 +3,14 @@ package handlers
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/InternPulse/famtrust-backend-auth/internal/jwtmod"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
@@ -25,10 +29,7 @@ func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
 		// Remove the "Bearer " prefix to get the actual token
 		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
-		claims := jwtmod.JwtClaim{}
-		token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtmod.JwtKey, nil
-		})
+		claims, err := jwtmod.ParseJWT(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, loginResponse{
 				StatusCode: http.StatusUnauthorized,
@@ -39,7 +40,9 @@ func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
+		// Reject tokens from sessions revoked since they were issued
+		user, err := h.models.Users().In(interfaces.Scope{UserID: claims.ID}).GetSessionUser(claims.ID)
+		if err != nil || sessionRevoked(claims, user) {
 			c.JSON(http.StatusUnauthorized, loginResponse{
 				StatusCode: http.StatusUnauthorized,
 				Status:     "error",
@@ -49,8 +52,163 @@ func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("token", token.Raw)
+		c.Set("token", tokenString)
 		c.Set("UserID", claims.ID)
+		c.Set("GroupID", user.DefaultGroup)
+		c.Set("Session2FA", claims.TwoFactor)
 		c.Next()
 	}
 }
+
+// sessionRevoked reports whether the token was issued before the user's
+// sessions were last revoked, by a password reset or lockout. Both times are
+// whole seconds, so a login in the same second as the revocation, like the
+// one right after a password reset, keeps its token.
+func sessionRevoked(claims *jwtmod.JwtClaim, user *interfaces.User) bool {
+	return user.SessionsRevokedAt != nil && claims.IssuedAt < user.SessionsRevokedAt.Unix()
+}
+
+// callerScope limits user queries to the caller set by AuthMiddleware and
+// their family group.
+func callerScope(c *gin.Context) interfaces.Scope {
+	var scope interfaces.Scope
+	if userID, ok := c.Get("UserID"); ok {
+		scope.UserID = userID.(uuid.UUID)
+	}
+	if groupID, ok := c.Get("GroupID"); ok {
+		scope.GroupID = groupID.(uuid.UUID)
+	}
+	return scope
+}
+
+// Principal is the authenticated user behind a request, loaded at most once
+// per request by the permission middlewares.
+type Principal struct {
+	User *interfaces.User
+	// RolePermissions are held through the user's role and its parents
+	RolePermissions []string
+	// Permissions adds the user's active grants to RolePermissions
+	Permissions []string
+	// Sources and Session let Can evaluate policy conditions on permissions
+	Sources []policy.Source
+	Session policy.Context
+}
+
+// Can reports whether the user holds perm and no condition on it fails for
+// the current session. Conditions needing request details, like amounts,
+// don't apply to this service's own routes.
+func (p *Principal) Can(perm rbac.Permission) bool {
+	return policy.Decide(p.Sources, string(perm), p.Session).Allowed
+}
+
+const principalKey = "principal"
+
+// principalFrom returns the principal stored by RequirePermission or RequireAnyPermission.
+func principalFrom(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// loadPrincipal returns the request's principal, loading it from the database
+// on first use. It must run after AuthMiddleware.
+func (h *Handlers) loadPrincipal(c *gin.Context) (*Principal, bool) {
+	if principal, ok := principalFrom(c); ok {
+		return principal, true
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return nil, false
+	}
+
+	user, err := h.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, couldn't verify user",
+		})
+		return nil, false
+	}
+
+	held := loadHeldPermissions(h.models, user)
+	session2FA := c.GetBool("Session2FA")
+	principal := &Principal{
+		User:            user,
+		RolePermissions: held.Role,
+		Permissions:     held.Permissions(),
+		Sources:         held.Sources,
+		Session: policy.Context{
+			Now:           time.Now(),
+			SubjectID:     user.ID,
+			EmailVerified: user.IsVerified,
+			Session2FA:    &session2FA,
+		},
+	}
+	c.Set(principalKey, principal)
+
+	return principal, true
+}
+
+func abortForbidden(c *gin.Context, missing []string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse{
+		StatusCode:         http.StatusForbidden,
+		Status:             "error",
+		Message:            "User does not have the necessary permissions to perform action",
+		MissingPermissions: missing,
+	})
+}
+
+// RequirePermission allows the request through only if the user holds every one of perms.
+func (h *Handlers) RequirePermission(perms ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := h.loadPrincipal(c)
+		if !ok {
+			return
+		}
+
+		var missing []string
+		for _, perm := range perms {
+			if !principal.Can(perm) {
+				missing = append(missing, string(perm))
+			}
+		}
+
+		if len(missing) > 0 {
+			abortForbidden(c, missing)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAnyPermission allows the request through if the user holds at least one of perms.
+func (h *Handlers) RequireAnyPermission(perms ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := h.loadPrincipal(c)
+		if !ok {
+			return
+		}
+
+		var missing []string
+		for _, perm := range perms {
+			if principal.Can(perm) {
+				c.Next()
+				return
+			}
+			missing = append(missing, string(perm))
+		}
+
+		abortForbidden(c, missing)
+	}
+}
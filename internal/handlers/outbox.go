@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OutboxHandlers struct {
+	models interfaces.Models
+}
+
+// cleanOutboxEmail leaves out the bodies, which can hold codes and links.
+func cleanOutboxEmail(e interfaces.OutboxEmail) outboxEmail {
+	return outboxEmail{
+		Id:            e.ID,
+		To:            e.To,
+		Subject:       e.Subject,
+		Status:        e.Status,
+		Attempts:      e.Attempts,
+		NextAttemptAt: e.NextAttemptAt,
+		LastError:     e.LastError,
+		SentAt:        e.SentAt,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+// loadOutboxEmail loads the email named by the emailID path parameter,
+// writing an error if there is none.
+func (oh *OutboxHandlers) loadOutboxEmail(c *gin.Context) (*interfaces.OutboxEmail, bool) {
+	emailID, err := uuid.Parse(c.Param("emailID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid email ID",
+		})
+		return nil, false
+	}
+
+	email, err := oh.models.Outbox().GetEmailByID(emailID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Email does not exist",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving email",
+		})
+		return nil, false
+	}
+
+	return email, true
+}
+
+// @Summary		Get Outbox Emails
+// @Description	List up to 500 emails in the outbox by status, most recent first. Dead emails failed every delivery attempt; pending emails with attempts are being retried - Requires the canManageEmail permission
+// @Tags			Outbox
+// @ID				outbox-emails
+// @Security		BearerAuth
+// @Produce		json
+// @Param			status	query	string	false	"dead (default), pending or sent"
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/outbox [get]
+func (oh *OutboxHandlers) GetOutboxEmails(c *gin.Context) {
+	status := c.DefaultQuery("status", interfaces.EmailDead)
+	if !slices.Contains([]string{interfaces.EmailPending, interfaces.EmailSent, interfaces.EmailDead}, status) {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "status must be dead, pending or sent",
+		})
+		return
+	}
+
+	emails, err := oh.models.Outbox().GetEmailsByStatus(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving emails",
+		})
+		return
+	}
+
+	cleanEmails := []outboxEmail{}
+	for _, email := range emails {
+		cleanEmails = append(cleanEmails, cleanOutboxEmail(email))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Emails retrieved successfully",
+		"emails":     cleanEmails,
+	})
+}
+
+// @Summary		Get an Outbox Email
+// @Description	Get an email's delivery status, attempts and last error - Requires the canManageEmail permission
+// @Tags			Outbox
+// @ID				outbox-email
+// @Security		BearerAuth
+// @Produce		json
+// @Param			emailID	path	string	true	"Email ID"
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/outbox/{emailID} [get]
+func (oh *OutboxHandlers) GetOutboxEmail(c *gin.Context) {
+	email, ok := oh.loadOutboxEmail(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Email retrieved successfully",
+		"email":      cleanOutboxEmail(*email),
+	})
+}
+
+// @Summary		Retry an Outbox Email
+// @Description	Queue an unsent email for immediate delivery with a fresh set of attempts, typically after fixing whatever made it dead - Requires the canManageEmail permission
+// @Tags			Outbox
+// @ID				retry-outbox-email
+// @Security		BearerAuth
+// @Produce		json
+// @Param			emailID	path	string	true	"Email ID"
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/outbox/{emailID}/retry [post]
+func (oh *OutboxHandlers) RetryOutboxEmail(c *gin.Context) {
+	email, ok := oh.loadOutboxEmail(c)
+	if !ok {
+		return
+	}
+
+	err := oh.models.Outbox().RetryEmail(email.ID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusConflict, loginResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "error",
+			Message:    "Email has already been sent",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to retry email",
+		})
+		return
+	}
+
+	email, ok = oh.loadOutboxEmail(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Email queued for delivery",
+		"email":      cleanOutboxEmail(*email),
+	})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/images"
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/storage"
+	"github.com/google/uuid"
+)
+
+// PictureURLSecret signs the profile picture URLs given to a user's family.
+var PictureURLSecret []byte
+
+const (
+	// pictureURLLifetime is roughly how long a signed picture URL works
+	pictureURLLifetime = time.Hour
+	// pictureURLWindow rounds expiry times up, so everyone asking within
+	// the same window gets the same URL and a CDN can cache it
+	pictureURLWindow = 15 * time.Minute
+)
+
+// pictureSignature signs a picture's file name and the URL's expiry.
+func pictureSignature(name string, expires int64) string {
+	mac := hmac.New(sha256.New, PictureURLSecret)
+	mac.Write([]byte(name + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// pictureURL is the URL a profile's picture is given out at: as stored if
+// the picture is public, otherwise signed and expiring. Profiles without a
+// picture get a default avatar.
+func pictureURL(p *interfaces.UserProfile, now time.Time) string {
+	if p.ProfilePictureUrl == "" {
+		return avatarURL(p.UserID, images.Initials(p.FirstName, p.LastName))
+	}
+	if p.PictureIsPublic {
+		return p.ProfilePictureUrl
+	}
+	key, ok := storage.ProfilePictureKey(p.ProfilePictureUrl)
+	if !ok {
+		return p.ProfilePictureUrl
+	}
+	name := strings.TrimPrefix(key, storage.ProfilePicturePrefix)
+
+	expires := now.Add(pictureURLLifetime).Truncate(pictureURLWindow).Add(pictureURLWindow).Unix()
+	query := url.Values{
+		"expires": {strconv.FormatInt(expires, 10)},
+		"sig":     {pictureSignature(name, expires)},
+	}
+	return p.ProfilePictureUrl + "?" + query.Encode()
+}
+
+// checkPictureSignature reports whether a signed picture URL is genuine and
+// unexpired, and when it expires.
+func checkPictureSignature(name, expiresStr, sig string, now time.Time) (time.Time, bool) {
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expiresAt := time.Unix(expires, 0)
+	if !expiresAt.After(now) {
+		return time.Time{}, false
+	}
+	if !hmac.Equal([]byte(sig), []byte(pictureSignature(name, expires))) {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// userPictureURL is the URL of a user's picture, or of an avatar with the
+// first letter of their email address if they have no profile yet.
+func userPictureURL(u *interfaces.User, now time.Time) string {
+	if u.UserProfile.UserID == uuid.Nil {
+		name, _, _ := strings.Cut(u.Email, "@")
+		return avatarURL(u.ID, images.Initials(name))
+	}
+	return pictureURL(&u.UserProfile, now)
+}
+
+// avatarPrefix starts the names of generated avatars, which are never stored
+const avatarPrefix = "avatar-"
+
+// avatarURL is the URL of a user's default avatar, e.g.
+// "images/profile-pic/avatar-<userID>-AO.png". The initials are part of the
+// URL, so it changes when they do and the avatar can be cached for good.
+func avatarURL(userID uuid.UUID, initials string) string {
+	name := avatarPrefix + userID.String()
+	if initials != "" {
+		name += "-" + url.PathEscape(initials)
+	}
+	return storage.ProfilePictureURL(name + ".png")
+}
+
+// parseAvatarName reads the user ID, initials and format, "png" or "svg",
+// from an avatar's name. It reports false for names that aren't avatars'.
+func parseAvatarName(name string) (userID uuid.UUID, initials, format string, ok bool) {
+	rest, ok := strings.CutPrefix(name, avatarPrefix)
+	if !ok {
+		return uuid.Nil, "", "", false
+	}
+	ext := path.Ext(rest)
+	if ext != ".png" && ext != ".svg" {
+		return uuid.Nil, "", "", false
+	}
+	rest = strings.TrimSuffix(rest, ext)
+
+	// User IDs have a fixed length, and contain hyphens themselves
+	idLength := len(uuid.Nil.String())
+	if len(rest) < idLength {
+		return uuid.Nil, "", "", false
+	}
+	userID, err := uuid.Parse(rest[:idLength])
+	if err != nil {
+		return uuid.Nil, "", "", false
+	}
+	if initials = rest[idLength:]; initials != "" {
+		initials, ok = strings.CutPrefix(initials, "-")
+		if !ok || initials == "" || utf8.RuneCountInString(initials) > 2 {
+			return uuid.Nil, "", "", false
+		}
+	}
+	for _, r := range initials {
+		if !images.ValidInitial(r) {
+			return uuid.Nil, "", "", false
+		}
+	}
+	return userID, initials, strings.TrimPrefix(ext, "."), true
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// profileHistoryPageSize is how many changes a page of history holds
+const profileHistoryPageSize = 50
+
+// @Summary		Get the User's Profile History
+// @Description	Get the changes made to the user's profile, newest first, 50 at a time. Each lists the fields changed with their old and new values, who changed them and when. NIN and BVN changes are marked redacted without their values. Pass nextBefore from a page as before to get the next one
+// @Tags			User-Profiles
+// @ID				profile-history
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		401	{object}	loginSampleResponseError401
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			before	query	int	false	"Only changes to versions before this one"
+// @Router			/profile/history [get]
+func (uh *UserHandlers) GetProfileHistory(c *gin.Context) {
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	uh.writeProfileHistory(c, UserID.(uuid.UUID))
+}
+
+// @Summary		Get a Family Member's Profile History
+// @Description	Get the changes made to the profile of a member of the user's family group, newest first, 50 at a time. NIN and BVN changes are marked redacted without their values. Pass nextBefore from a page as before to get the next one - Requires the canViewProfileHistory permission
+// @Tags			User-Profiles
+// @ID				member-profile-history
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			userID	path	string	true	"User ID"
+// @Param			before	query	int		false	"Only changes to versions before this one"
+// @Router			/users/{userID}/profile/history [get]
+func (uh *UserHandlers) GetMemberProfileHistory(c *gin.Context) {
+	principal, ok := familyPrincipal(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid user ID",
+		})
+		return
+	}
+
+	member, err := uh.models.Users().GetUserByDefaultGroup(memberID, principal.User.DefaultGroup)
+	if err != nil {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "User is not a member of your family group",
+		})
+		return
+	}
+
+	uh.writeProfileHistory(c, member.ID)
+}
+
+// writeProfileHistory writes a page of a user's profile history, from before
+// the version in the before query parameter if it is set.
+func (uh *UserHandlers) writeProfileHistory(c *gin.Context, userID uuid.UUID) {
+	before := 0
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		var err error
+		if before, err = strconv.Atoi(beforeStr); err != nil || before < 1 {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "before must be a profile version",
+			})
+			return
+		}
+	}
+
+	changes, err := uh.models.Users().In(callerScope(c)).GetProfileHistory(userID, before, profileHistoryPageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving profile history",
+		})
+		return
+	}
+
+	history := []profileChange{}
+	for _, change := range changes {
+		history = append(history, profileChange{
+			Id:        change.ID,
+			Version:   change.Version,
+			Action:    change.Action,
+			ActorID:   change.ActorID,
+			Fields:    change.Fields,
+			ChangedAt: change.CreatedAt,
+		})
+	}
+
+	payload := gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Profile history retrieved successfully",
+		"changes":    history,
+	}
+	if len(changes) == profileHistoryPageSize {
+		payload["nextBefore"] = changes[len(changes)-1].Version
+	}
+
+	c.JSON(http.StatusOK, payload)
+}
@@ -0,0 +1,859 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoleHandlers struct {
+	models interfaces.Models
+	events interfaces.AccountEvents
+}
+
+// lookupPermissions loads the permissions with the given IDs, writing a 400
+// response naming any that don't exist.
+func (rh *RoleHandlers) lookupPermissions(c *gin.Context, permIDs []string) ([]interfaces.Permission, bool) {
+	perms, err := rh.models.Permissions().GetPermissionsByIDs(permIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving permissions",
+		})
+		return nil, false
+	}
+
+	var missing []string
+	for _, id := range permIDs {
+		if !slices.ContainsFunc(perms, func(p interfaces.Permission) bool { return p.ID == id }) {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Unknown permissions: " + strings.Join(missing, ", "),
+		})
+		return nil, false
+	}
+
+	return perms, true
+}
+
+// lookupParents checks that every role ID names an existing global role,
+// writing a 400 response naming any that don't.
+func (rh *RoleHandlers) lookupParents(c *gin.Context, roleIDs []string) bool {
+	var missing []string
+	for _, id := range roleIDs {
+		parent, err := rh.models.Roles().GetRoleByID(id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured while retrieving roles",
+			})
+			return false
+		}
+
+		// Family roles can't be inherited outside their family
+		if err != nil || parent.GroupID != nil {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Unknown parent roles: " + strings.Join(missing, ", "),
+		})
+		return false
+	}
+
+	return true
+}
+
+// globalRole loads the global role in the path, writing a 404 for roles that
+// don't exist or belong to a family, which are managed through /family-roles.
+func (rh *RoleHandlers) globalRole(c *gin.Context) (*interfaces.Role, bool) {
+	r, err := rh.models.Roles().GetRoleByID(c.Param("roleID"))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving role",
+		})
+		return nil, false
+	}
+
+	if err != nil || r.GroupID != nil {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Role does not exist",
+		})
+		return nil, false
+	}
+
+	return r, true
+}
+
+// validConditions checks every condition is well formed, writing a 400
+// response for the first that isn't.
+func validConditions(c *gin.Context, conds []policy.Condition) bool {
+	for _, cond := range conds {
+		if err := cond.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Invalid condition: " + err.Error(),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+func cleanRole(r interfaces.Role) role {
+	perms := []string{}
+	for _, perm := range r.Permissions {
+		perms = append(perms, perm.ID)
+	}
+	var parents []string
+	for _, parent := range r.Parents {
+		parents = append(parents, parent.ID)
+	}
+	return role{
+		Id:          r.ID,
+		Name:        r.Name,
+		GroupID:     r.GroupID,
+		Description: r.Description,
+		Inherits:    parents,
+		Permissions: perms,
+		Conditions:  r.Conditions,
+	}
+}
+
+// @Summary		Get All Roles
+// @Description	Get All Roles and their permissions, leaving out family roles, which are listed through /family-roles - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				all-roles
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/roles [get]
+func (rh *RoleHandlers) GetAllRoles(c *gin.Context) {
+	roles, err := rh.models.Roles().GetGlobalRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving roles",
+		})
+		return
+	}
+
+	cleanRoles := []role{}
+	for _, r := range roles {
+		cleanRoles = append(cleanRoles, cleanRole(r))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Roles retrieved successfully",
+		"roles":      cleanRoles,
+	})
+}
+
+// @Summary		Get One Role
+// @Description	Get One Role and its permissions - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				one-role
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string	true	"Role ID"
+// @Router			/roles/{roleID} [get]
+func (rh *RoleHandlers) GetRoleByID(c *gin.Context) {
+	r, ok := rh.globalRole(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Role retrieved successfully",
+		"role":       cleanRole(*r),
+	})
+}
+
+// @Summary		Create a Role
+// @Description	Create a Role with an optional list of existing permissions, parent roles to inherit permissions from and policy conditions - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				create-role
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		201
+// @Param			Role	body	createRoleRequest	true	"New role"
+// @Router			/roles [post]
+func (rh *RoleHandlers) CreateRole(c *gin.Context) {
+	var payload createRoleRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid role data",
+		})
+		return
+	}
+
+	// The ':' separator is reserved for family role IDs
+	if strings.Contains(payload.ID, ":") {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Role ID cannot contain ':'",
+		})
+		return
+	}
+
+	if !validConditions(c, payload.Conditions) {
+		return
+	}
+
+	newRole := interfaces.Role{
+		ID:          payload.ID,
+		Description: payload.Description,
+		Conditions:  payload.Conditions,
+	}
+
+	if len(payload.Permissions) > 0 {
+		perms, ok := rh.lookupPermissions(c, payload.Permissions)
+		if !ok {
+			return
+		}
+		newRole.Permissions = perms
+	}
+
+	if len(payload.Inherits) > 0 && !rh.lookupParents(c, payload.Inherits) {
+		return
+	}
+
+	if err := rh.models.Roles().CreateRole(&newRole); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "A role with that ID already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to create role",
+		})
+		return
+	}
+
+	if len(payload.Inherits) > 0 {
+		if err := rh.models.Roles().SetRoleParents(newRole.ID, payload.Inherits); err != nil {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "Role was created but its parent roles couldn't be set",
+			})
+			return
+		}
+		for _, id := range payload.Inherits {
+			newRole.Parents = append(newRole.Parents, interfaces.Role{ID: id})
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "Role created successfully",
+		"role":       cleanRole(newRole),
+	})
+}
+
+// @Summary		Update a Role
+// @Description	Update a Role's description when one is given, and replace its permissions or conditions when a list is given. Family roles are managed through /family-roles - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				update-role
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string				true	"Role ID"
+// @Param			Role	body	updateRoleRequest	true	"Updated role"
+// @Router			/roles/{roleID} [put]
+func (rh *RoleHandlers) UpdateRole(c *gin.Context) {
+	var payload updateRoleRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid role data",
+		})
+		return
+	}
+
+	existing, ok := rh.globalRole(c)
+	if !ok {
+		return
+	}
+
+	if !validConditions(c, payload.Conditions) {
+		return
+	}
+
+	if payload.Description != nil {
+		existing.Description = *payload.Description
+	}
+	if payload.Conditions != nil {
+		existing.Conditions = payload.Conditions
+	}
+	existing.Permissions = nil
+
+	if payload.Permissions != nil {
+		existing.Permissions = []interfaces.Permission{}
+		if len(payload.Permissions) > 0 {
+			perms, ok := rh.lookupPermissions(c, payload.Permissions)
+			if !ok {
+				return
+			}
+			existing.Permissions = perms
+		}
+	}
+
+	if err := rh.models.Roles().UpdateRoleByID(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to update role",
+		})
+		return
+	}
+
+	updated, err := rh.models.Roles().GetRoleByID(existing.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "Role was updated but couldn't be retrieved",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Role updated successfully",
+		"role":       cleanRole(*updated),
+	})
+}
+
+// @Summary		Delete a Role
+// @Description	Delete a Role no user currently holds and no other role inherits from. Family roles are managed through /family-roles - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				delete-role
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string	true	"Role ID"
+// @Router			/roles/{roleID} [delete]
+func (rh *RoleHandlers) DeleteRole(c *gin.Context) {
+	roleID := c.Param("roleID")
+	if _, ok := rh.globalRole(c); !ok {
+		return
+	}
+
+	holders, err := rh.models.Roles().CountUsersWithRole(roleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while checking role holders",
+		})
+		return
+	}
+
+	if holders > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"statusCode": http.StatusConflict,
+			"status":     "error",
+			"message":    "Role is still held by users and cannot be deleted",
+			"holders":    holders,
+		})
+		return
+	}
+
+	children, err := rh.models.Roles().GetChildRoleIDs(roleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while checking inheriting roles",
+		})
+		return
+	}
+
+	if len(children) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"statusCode":  http.StatusConflict,
+			"status":      "error",
+			"message":     "Role is inherited by other roles and cannot be deleted",
+			"inheritedBy": children,
+		})
+		return
+	}
+
+	if err := rh.models.Roles().DeleteRoleByID(roleID); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to delete role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse{
+		StatusCode: http.StatusOK,
+		Status:     "success",
+		Message:    "Role deleted successfully",
+	})
+}
+
+// @Summary		Set a Role's Parent Roles
+// @Description	Replace the roles a Role inherits permissions from. An empty list removes all parents; changes that would make a role inherit from itself are rejected - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				set-role-parents
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		409
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID	path	string				true	"Role ID"
+// @Param			Parents	body	roleParentsRequest	true	"Parent role IDs"
+// @Router			/roles/{roleID}/parents [put]
+func (rh *RoleHandlers) SetRoleParents(c *gin.Context) {
+	var payload roleParentsRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "A list of parent role IDs is required",
+		})
+		return
+	}
+
+	roleID := c.Param("roleID")
+	if _, ok := rh.globalRole(c); !ok {
+		return
+	}
+
+	if !rh.lookupParents(c, payload.Inherits) {
+		return
+	}
+
+	if err := rh.models.Roles().SetRoleParents(roleID, payload.Inherits); err != nil {
+		if errors.Is(err, interfaces.ErrRoleCycle) {
+			c.JSON(http.StatusConflict, loginResponse{
+				StatusCode: http.StatusConflict,
+				Status:     "error",
+				Message:    "Role cannot inherit from itself or from a role that inherits from it",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to set parent roles",
+		})
+		return
+	}
+
+	updated, err := rh.models.Roles().GetRoleByID(roleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "Parent roles were set but the role couldn't be retrieved",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Parent roles set successfully",
+		"role":       cleanRole(*updated),
+	})
+}
+
+// @Summary		Attach Permissions to a Role
+// @Description	Attach existing Permissions to a Role - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				attach-role-permissions
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID		path	string					true	"Role ID"
+// @Param			Permissions	body	rolePermissionsRequest	true	"Permission IDs to attach"
+// @Router			/roles/{roleID}/permissions [post]
+func (rh *RoleHandlers) AddPermissionsToRole(c *gin.Context) {
+	var payload rolePermissionsRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "A list of permission IDs is required",
+		})
+		return
+	}
+
+	roleID := c.Param("roleID")
+	if _, ok := rh.globalRole(c); !ok {
+		return
+	}
+
+	perms, ok := rh.lookupPermissions(c, payload.Permissions)
+	if !ok {
+		return
+	}
+
+	if err := rh.models.Roles().AddPermissionsToRole(roleID, perms); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to attach permissions",
+		})
+		return
+	}
+
+	updated, err := rh.models.Roles().GetRoleByID(roleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "Permissions were attached but the role couldn't be retrieved",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Permissions attached successfully",
+		"role":       cleanRole(*updated),
+	})
+}
+
+// @Summary		Detach a Permission from a Role
+// @Description	Detach a Permission from a Role - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				detach-role-permission
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			roleID			path	string	true	"Role ID"
+// @Param			permissionID	path	string	true	"Permission ID"
+// @Router			/roles/{roleID}/permissions/{permissionID} [delete]
+func (rh *RoleHandlers) RemovePermissionFromRole(c *gin.Context) {
+	roleID := c.Param("roleID")
+	existing, ok := rh.globalRole(c)
+	if !ok {
+		return
+	}
+
+	permissionID := c.Param("permissionID")
+	idx := slices.IndexFunc(existing.Permissions, func(p interfaces.Permission) bool { return p.ID == permissionID })
+	if idx < 0 {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Role does not have that permission",
+		})
+		return
+	}
+
+	if err := rh.models.Roles().RemovePermissionsFromRole(roleID, existing.Permissions[idx:idx+1]); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to detach permission",
+		})
+		return
+	}
+
+	existing.Permissions = slices.Delete(existing.Permissions, idx, idx+1)
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Permission detached successfully",
+		"role":       cleanRole(*existing),
+	})
+}
+
+// @Summary		Get All Permissions
+// @Description	Get All Permissions - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				all-permissions
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/permissions [get]
+func (rh *RoleHandlers) GetAllPermissions(c *gin.Context) {
+	perms, err := rh.models.Permissions().GetAllPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving permissions",
+		})
+		return
+	}
+
+	cleanPerms := []permission{}
+	for _, perm := range perms {
+		cleanPerms = append(cleanPerms, permission{Id: perm.ID, Description: perm.Description, MinTier: perm.MinTier})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode":  http.StatusOK,
+		"status":      "success",
+		"message":     "Permissions retrieved successfully",
+		"permissions": cleanPerms,
+	})
+}
+
+// @Summary		Create a Permission
+// @Description	Create a Permission - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				create-permission
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		201
+// @Param			Permission	body	createPermissionRequest	true	"New permission"
+// @Router			/permissions [post]
+func (rh *RoleHandlers) CreatePermission(c *gin.Context) {
+	var payload createPermissionRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid permission data",
+		})
+		return
+	}
+
+	if _, err := rbac.ParseKYCTier(payload.MinTier); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid minTier, use emailVerified, phoneVerified, ninVerified or bvnVerified",
+		})
+		return
+	}
+
+	perm := interfaces.Permission{
+		ID:          payload.ID,
+		Description: payload.Description,
+		MinTier:     payload.MinTier,
+	}
+
+	if err := rh.models.Permissions().CreatePermission(&perm); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "A permission with that ID already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to create permission",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "Permission created successfully",
+		"permission": permission{Id: perm.ID, Description: perm.Description, MinTier: perm.MinTier},
+	})
+}
+
+// @Summary		Update a Permission
+// @Description	Update a Permission's description and minimum KYC tier - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				update-permission
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Failure		400
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			permissionID	path	string					true	"Permission ID"
+// @Param			Permission		body	updatePermissionRequest	true	"Updated permission"
+// @Router			/permissions/{permissionID} [put]
+func (rh *RoleHandlers) UpdatePermission(c *gin.Context) {
+	var payload updatePermissionRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid permission data",
+		})
+		return
+	}
+
+	perm, err := rh.models.Permissions().GetPermission(&interfaces.Permission{ID: c.Param("permissionID")})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Permission does not exist",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving permission",
+		})
+		return
+	}
+
+	perm.Description = payload.Description
+	if payload.MinTier != nil {
+		if _, err := rbac.ParseKYCTier(*payload.MinTier); err != nil {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Invalid minTier, use emailVerified, phoneVerified, ninVerified or bvnVerified",
+			})
+			return
+		}
+		perm.MinTier = *payload.MinTier
+	}
+	if err := rh.models.Permissions().UpdatePermission(perm); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to update permission",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Permission updated successfully",
+		"permission": permission{Id: perm.ID, Description: perm.Description, MinTier: perm.MinTier},
+	})
+}
+
+// @Summary		Delete a Permission
+// @Description	Delete a Permission and detach it from every role - Requires the canManageRoles permission
+// @Tags			Roles
+// @ID				delete-permission
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Param			permissionID	path	string	true	"Permission ID"
+// @Router			/permissions/{permissionID} [delete]
+func (rh *RoleHandlers) DeletePermission(c *gin.Context) {
+	perm, err := rh.models.Permissions().GetPermission(&interfaces.Permission{ID: c.Param("permissionID")})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "Permission does not exist",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving permission",
+		})
+		return
+	}
+
+	if err := rh.models.Permissions().DeletePermission(*perm); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to delete permission",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse{
+		StatusCode: http.StatusOK,
+		Status:     "success",
+		Message:    "Permission deleted successfully",
+	})
+}
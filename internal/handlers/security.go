@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SecurityHandlers struct {
+	models interfaces.Models
+	events interfaces.AccountEvents
+}
+
+// accountEvent describes an event on the user's account caused by the
+// request, for security alerts.
+func accountEvent(c *gin.Context, eventType string, userID uuid.UUID) *interfaces.AccountEvent {
+	return &interfaces.AccountEvent{
+		Type:      eventType,
+		UserID:    userID,
+		At:        time.Now(),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// deviceFingerprint identifies the client by its X-Device-ID header, or by
+// its user agent if it doesn't send one.
+func deviceFingerprint(c *gin.Context) string {
+	id := c.GetHeader("X-Device-ID")
+	if id == "" {
+		id = "ua:" + c.Request.UserAgent()
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// @Summary		Turn 2FA On or Off
+// @Description	Turn two-factor authentication on or off for the user's account. The user's password is required, and turning 2FA off sends a security alert
+// @Tags			Security
+// @ID				set-2fa
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			Settings	body	twoFactorRequest	true	"New 2FA setting and the user's password"
+// @Failure		400
+// @Failure		401
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/security/2fa [put]
+func (sh *SecurityHandlers) SetTwoFactor(c *gin.Context) {
+	var payload twoFactorRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "enabled and password are required",
+		})
+		return
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	user, err := sh.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	valid, err := sh.models.Users().PasswordMatches(user.PasswordHash, payload.Password)
+	if err != nil || !valid {
+		c.JSON(http.StatusUnauthorized, loginResponse{
+			StatusCode: http.StatusUnauthorized,
+			Status:     "error",
+			Message:    "Invalid Credentials",
+		})
+		return
+	}
+
+	if *payload.Enabled != user.Has2FA {
+		if err := sh.models.Users().In(callerScope(c)).SetHas2FA(user.ID, *payload.Enabled); err != nil {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured, failed to update 2FA",
+			})
+			return
+		}
+		if !*payload.Enabled {
+			sh.events.Publish(accountEvent(c, interfaces.EventTwoFactorDisabled, user.ID))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "2FA setting updated successfully",
+		"has2FA":     *payload.Enabled,
+	})
+}
+
+// @Summary		Get Security Alert Settings
+// @Description	List the account events the user is emailed about, whether each is muted, and whether it is critical. Critical alerts can't be muted
+// @Tags			Security
+// @ID				security-alerts
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/security/alerts [get]
+func (sh *SecurityHandlers) GetAlertSettings(c *gin.Context) {
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	user, err := sh.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Alert settings retrieved successfully",
+		"alerts":     alertSettings(user),
+	})
+}
+
+func alertSettings(user *interfaces.User) []alertSetting {
+	settings := []alertSetting{}
+	for _, event := range interfaces.AlertEvents {
+		critical := interfaces.IsCriticalEvent(event)
+		settings = append(settings, alertSetting{
+			Event:    event,
+			Critical: critical,
+			Muted:    !critical && slices.Contains(user.MutedAlerts, event),
+		})
+	}
+	return settings
+}
+
+// @Summary		Mute Security Alerts
+// @Description	Set the non-critical account events the user isn't emailed about, replacing the current list. Critical alerts (passwordChanged, twoFactorDisabled) can't be muted
+// @Tags			Security
+// @ID				mute-security-alerts
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			Alerts	body	muteAlertsRequest	true	"Alerts to mute; an empty list unmutes all"
+// @Failure		400
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/security/alerts [put]
+func (sh *SecurityHandlers) MuteAlerts(c *gin.Context) {
+	var payload muteAlertsRequest
+	if err := c.ShouldBindBodyWithJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "muted must be a list of alerts",
+		})
+		return
+	}
+
+	muted := []string{}
+	for _, event := range payload.Muted {
+		if !slices.Contains(interfaces.AlertEvents, event) {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Unknown alert " + event,
+			})
+			return
+		}
+		if interfaces.IsCriticalEvent(event) {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    event + " is a critical alert and can't be muted",
+			})
+			return
+		}
+		if !slices.Contains(muted, event) {
+			muted = append(muted, event)
+		}
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	if err := sh.models.Users().In(callerScope(c)).SetMutedAlerts(UserID.(uuid.UUID), muted); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to update alert settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Alert settings updated successfully",
+		"alerts":     alertSettings(&interfaces.User{MutedAlerts: muted}),
+	})
+}
+
+// lockoutPage asks the user to confirm the lockout. Following the link only
+// shows this page, so mail scanners that open links can't lock accounts.
+var lockoutPage = template.Must(template.New("lockout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>FamTrust</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;max-width:480px;margin:48px auto;padding:0 24px;color:#1f2933;">
+<h1 style="color:#0b6e4f;">FamTrust</h1>
+{{if .Done}}<p>{{.Message}}</p>{{else}}
+<p>If you didn't do what the alert described, lock your account. We'll freeze it and sign you out everywhere, and FamTrust support will help you get back in.</p>
+<form method="post" action="?code={{.Code}}"><button type="submit" style="padding:12px 24px;background:#c81e1e;color:#ffffff;border:0;border-radius:4px;font-size:16px;">Lock my account</button></form>
+{{end}}
+</body>
+</html>`))
+
+// @Summary		Confirm Locking an Account ("This Wasn't Me")
+// @Description	The page the link in a security alert opens, asking the user to confirm locking their account
+// @Tags			Security
+// @ID				security-lockout-page
+// @Produce		html
+// @Param			code	query	string	true	"Lockout code from the alert email"
+// @Success		200
+// @Router			/security/lockout [get]
+func (sh *SecurityHandlers) GetLockoutPage(c *gin.Context) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := lockoutPage.Execute(c.Writer, gin.H{"Code": c.Query("code")}); err != nil {
+		log.Printf("Failed to render lockout page: %v", err)
+	}
+}
+
+// @Summary		Lock an Account ("This Wasn't Me")
+// @Description	Used from the link in a security alert when the user didn't do what it reports. Freezes the account, so it can't log in or be authorized for anything, and signs it out of every session; FamTrust support restores access. Form posts from the confirmation page get an HTML reply
+// @Tags			Security
+// @ID				security-lockout
+// @Produce		json
+// @Param			code	query	string	true	"Lockout code from the alert email"
+// @Failure		400
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/security/lockout [post]
+func (sh *SecurityHandlers) LockAccount(c *gin.Context) {
+	codeID, err := uuid.Parse(c.Query("code"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid or expired link",
+		})
+		return
+	}
+
+	code, err := sh.models.VerCodes().GetLockoutCodeByID(codeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, loginResponse{
+				StatusCode: http.StatusBadRequest,
+				Status:     "error",
+				Message:    "Invalid or expired link",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	if err := sh.models.Users().In(interfaces.Scope{UserID: code.UserID}).LockAccount(code.UserID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to lock account",
+		})
+		return
+	}
+
+	// The account is locked; the other alerts' links have nothing left to do
+	if err := sh.models.VerCodes().DeleteLockoutCodesByUserID(code.UserID); err != nil {
+		log.Printf("Failed to delete lockout codes of user %s: %v", code.UserID, err)
+	}
+
+	if err := sh.models.AuditLogs().CreateAuditLogs([]interfaces.AuditLog{{
+		Event:     "account.locked",
+		ActorID:   &code.UserID,
+		SubjectID: &code.UserID,
+		Allowed:   true,
+		Reason:    "owner reported activity from a security alert as not theirs",
+	}}); err != nil {
+		log.Printf("Failed to audit lockout of user %s: %v", code.UserID, err)
+	}
+
+	message := "Your account has been frozen and signed out everywhere. Contact FamTrust support to get back in"
+	if c.ContentType() == "application/x-www-form-urlencoded" {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := lockoutPage.Execute(c.Writer, gin.H{"Done": true, "Message": message}); err != nil {
+			log.Printf("Failed to render lockout page: %v", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse{
+		StatusCode: http.StatusOK,
+		Status:     "success",
+		Message:    message,
+	})
+}
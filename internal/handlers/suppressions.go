@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/mailer"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EmailWebhookSecret must be sent in the X-Webhook-Secret header of bounce
+// and complaint webhooks. The webhook is disabled while it is empty.
+var EmailWebhookSecret []byte
+
+// maxFeedbackSize caps the size of a bounce or complaint webhook body
+const maxFeedbackSize = 1 << 20 // 1 MiB
+
+func cleanSuppression(s interfaces.SuppressedAddress) suppression {
+	return suppression{
+		Email:     s.Email,
+		Reason:    s.Reason,
+		Detail:    s.Detail,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// readFeedback parses a webhook body: a JSON array of events, or a raw DSN
+// or abuse report email.
+func readFeedback(c *gin.Context) ([]mailer.Feedback, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFeedbackSize)
+
+	if c.ContentType() != "application/json" {
+		return mailer.ParseReport(c.Request.Body)
+	}
+
+	var events []emailFeedbackRequest
+	if err := c.ShouldBindJSON(&events); err != nil {
+		return nil, err
+	}
+
+	var feedback []mailer.Feedback
+	for _, event := range events {
+		if event.Type != interfaces.SuppressedBounce && event.Type != interfaces.SuppressedComplaint {
+			return nil, errors.New("type must be bounce or complaint")
+		}
+		feedback = append(feedback, mailer.Feedback{
+			Recipient: event.Email,
+			Type:      event.Type,
+			Status:    event.Status,
+			Detail:    event.Detail,
+		})
+	}
+	return feedback, nil
+}
+
+// @Summary		Report Email Bounces and Complaints
+// @Description	Webhook for the mail provider to report bounced and complained-about emails. Send either a raw delivery status notification (RFC 3464) or abuse report (RFC 5965) email, or a JSON array of events. Hard bounces and complaints put the address on the suppression list; temporary (4.x.x) bounces are ignored. Requires the X-Webhook-Secret header to match EMAIL_WEBHOOK_SECRET
+// @Tags			Outbox
+// @ID				email-feedback-webhook
+// @Accept			json,message/rfc822
+// @Produce		json
+// @Param			X-Webhook-Secret	header	string					true	"Shared webhook secret"
+// @Param			Events				body	[]emailFeedbackRequest	false	"Bounce and complaint events, when not sending a raw report"
+// @Failure		400
+// @Failure		401
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/webhooks/email-feedback [post]
+func (oh *OutboxHandlers) ReceiveEmailFeedback(c *gin.Context) {
+	if len(EmailWebhookSecret) == 0 {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Email feedback webhook is not enabled",
+		})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Webhook-Secret")), EmailWebhookSecret) != 1 {
+		c.JSON(http.StatusUnauthorized, loginResponse{
+			StatusCode: http.StatusUnauthorized,
+			Status:     "error",
+			Message:    "Invalid webhook secret",
+		})
+		return
+	}
+
+	feedback, err := readFeedback(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, loginResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "error",
+			Message:    "Invalid bounce or complaint report: " + err.Error(),
+		})
+		return
+	}
+
+	suppressed := []string{}
+	ignored := 0
+	for _, f := range feedback {
+		if !f.Permanent() || f.Recipient == "" {
+			ignored++
+			continue
+		}
+
+		err := oh.models.Suppressions().SuppressAddress(&interfaces.SuppressedAddress{
+			Email:  f.Recipient,
+			Reason: f.Type,
+			Detail: strings.TrimSpace(f.Status + " " + f.Detail),
+		})
+		if err != nil {
+			log.Printf("Failed to suppress %s after %s: %v", f.Recipient, f.Type, err)
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "An error occured, failed to update the suppression list",
+			})
+			return
+		}
+		suppressed = append(suppressed, strings.ToLower(f.Recipient))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Feedback processed successfully",
+		"suppressed": suppressed,
+		"ignored":    ignored,
+	})
+}
+
+// @Summary		Get Suppressed Email Addresses
+// @Description	List the addresses no email is sent to because mail to them hard-bounced or was marked as spam, most recent first - Requires the canManageEmail permission
+// @Tags			Outbox
+// @ID				email-suppressions
+// @Security		BearerAuth
+// @Produce		json
+// @Failure		403
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/suppressions [get]
+func (oh *OutboxHandlers) GetSuppressions(c *gin.Context) {
+	suppressions, err := oh.models.Suppressions().GetSuppressions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured while retrieving suppressed addresses",
+		})
+		return
+	}
+
+	cleanSuppressions := []suppression{}
+	for _, s := range suppressions {
+		cleanSuppressions = append(cleanSuppressions, cleanSuppression(s))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode":   http.StatusOK,
+		"status":       "success",
+		"message":      "Suppressed addresses retrieved successfully",
+		"suppressions": cleanSuppressions,
+	})
+}
+
+// @Summary		Remove a Suppressed Email Address
+// @Description	Take an address off the suppression list, once its owner has fixed their mailbox, so email is sent to it again. Dead emails to it can then be retried from the outbox - Requires the canManageEmail permission
+// @Tags			Outbox
+// @ID				delete-email-suppression
+// @Security		BearerAuth
+// @Produce		json
+// @Param			email	path	string	true	"Email address"
+// @Failure		403
+// @Failure		404
+// @Failure		500	{object}	loginSampleResponseError500
+// @Success		200
+// @Router			/suppressions/{email} [delete]
+func (oh *OutboxHandlers) DeleteSuppression(c *gin.Context) {
+	err := oh.models.Suppressions().DeleteSuppression(c.Param("email"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, loginResponse{
+			StatusCode: http.StatusNotFound,
+			Status:     "error",
+			Message:    "Address is not suppressed",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to remove suppressed address",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "Address removed from the suppression list",
+	})
+}
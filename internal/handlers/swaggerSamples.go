@@ -46,8 +46,8 @@ type profileSampleResponse200 struct {
 		FirstName         string    `example:"Famtrust"`
 		LastName          string    `example:"Guru"`
 		Bio               string    `example:"The best FamTrust user of all time."`
-		NIN               uint      `example:"35473745433"`
-		BVN               uint      `example:"35473783473"`
+		NIN               string    `example:"*******5433"`
+		BVN               string    `example:"*******3473"`
 		ProfilePictureUrl string    `example:"https://image.famtrust.biz/dkkjieikdjfoej.jpg"`
 		CreatedAt         time.Time `example:"2024-07-22T14:30:00Z"`
 		UpdatedAt         time.Time `example:"2024-07-22T14:30:00Z"`
@@ -67,4 +67,7 @@ type validateSampleResponse200User struct {
 	IsVerified bool   `example:"true"`
 	LastLogin  string `example:"2024-07-22T14:30:00Z"`
 	Role       validateSampleResponseRole
+	KYCTier    string            `example:"ninVerified"`
+	Withheld   map[string]string `json:"withheldPermissions"`
+	Suppressed bool              `json:"emailSuppressed" example:"false"`
 }
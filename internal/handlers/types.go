@@ -3,12 +3,71 @@ package handlers
 import (
 	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
 	"github.com/google/uuid"
 )
 
 type loginRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+type signupRequest struct {
+	Email    string `json:"email" form:"email" binding:"required,email" example:"user@example.com"`
+	Password string `json:"password" form:"password" binding:"required" example:"password"`
+	Has2FA   bool   `json:"has2FA" form:"has2FA"`
+	Locale   string `json:"locale" form:"locale" binding:"omitempty,locale" example:"en"`
+}
+
+type createUserRequest struct {
+	Email    string `json:"email" form:"email" binding:"required,email" example:"user@example.com"`
+	Password string `json:"password" form:"password" binding:"required" example:"password"`
+	// RoleID defaults to member
+	RoleID string `json:"roleId" form:"roleID" example:"member"`
+	Has2FA bool   `json:"has2FA" form:"has2FA"`
+	Locale string `json:"locale" form:"locale" binding:"omitempty,locale" example:"en"`
+}
+
+type createProfileRequest struct {
+	FirstName              string `form:"firstName" binding:"required"`
+	LastName               string `form:"lastName" binding:"required"`
+	Bio                    string `form:"bio" binding:"required"`
+	NIN                    string `form:"nin" binding:"omitempty,identityNumber"`
+	BVN                    string `form:"bvn" binding:"omitempty,identityNumber"`
+	DateOfBirth            string `form:"dateOfBirth" binding:"omitempty,datetime=2006-01-02"`
+	FamilyGroupName        string `form:"familyGroupName"`
+	FamilyGroupDescription string `form:"familyGroupDescription"`
+	PictureIsPublic        *bool  `form:"pictureIsPublic"`
+}
+
+type updateProfileRequest struct {
+	FirstName       string `json:"firstName" form:"firstName" example:"Ada"`
+	LastName        string `json:"lastName" form:"lastName" example:"Obi"`
+	Bio             string `json:"bio" form:"bio"`
+	NIN             string `json:"nin" form:"nin" binding:"omitempty,identityNumber" example:"12345678901"`
+	BVN             string `json:"bvn" form:"bvn" binding:"omitempty,identityNumber" example:"12345678901"`
+	DateOfBirth     string `json:"dateOfBirth" form:"dateOfBirth" binding:"omitempty,datetime=2006-01-02" example:"1990-01-31"`
+	Locale          string `json:"locale" form:"locale" binding:"omitempty,locale" example:"yo"`
+	PictureIsPublic *bool  `json:"pictureIsPublic" form:"pictureIsPublic"`
+}
+
+// patchProfileRequest leaves out the fields that aren't changing, so unlike
+// updateProfileRequest an empty string clears a field. NIN and BVN are
+// checked by the handler, since the validator won't skip empty pointers.
+type patchProfileRequest struct {
+	FirstName       *string `json:"firstName" form:"firstName" binding:"omitnil,min=1" example:"Ada"`
+	LastName        *string `json:"lastName" form:"lastName" binding:"omitnil,min=1" example:"Obi"`
+	Bio             *string `json:"bio" form:"bio" example:""`
+	NIN             *string `json:"nin" form:"nin" example:"12345678901"`
+	BVN             *string `json:"bvn" form:"bvn" example:"12345678901"`
+	DateOfBirth     *string `json:"dateOfBirth" form:"dateOfBirth" example:"1990-01-31"`
+	Locale          *string `json:"locale" form:"locale" binding:"omitnil,locale" example:"yo"`
+	PictureIsPublic *bool   `json:"pictureIsPublic" form:"pictureIsPublic"`
+}
+
+type resetPasswordRequest struct {
+	NewPassword string `json:"newPassword" form:"newPassword" binding:"required" example:"password"`
 }
 
 type validateResponse struct {
@@ -26,6 +85,13 @@ type loginResponse struct {
 	Token      string `json:"token,omitempty"`
 }
 
+type forbiddenResponse struct {
+	StatusCode         uint     `json:"statusCode"`
+	Status             string   `json:"status"`
+	Message            string   `json:"message"`
+	MissingPermissions []string `json:"missingPermissions"`
+}
+
 type cleanUserData struct {
 	Id           uuid.UUID `json:"id"`
 	Email        string    `json:"email"`
@@ -35,9 +101,262 @@ type cleanUserData struct {
 	IsVerified   bool      `json:"isVerified"`
 	IsFrozen     bool      `json:"isFrozen"`
 	LastLogin    time.Time `json:"lastLogin"`
+	Grants       []grant   `json:"grants,omitempty"`
+	KYCTier      string    `json:"kycTier,omitempty"`
+	// WithheldPermissions maps permissions the role or grants give but the
+	// KYC tier doesn't yet allow to the tier each needs
+	WithheldPermissions map[string]string `json:"withheldPermissions,omitempty"`
+	// EmailSuppressed is set when mail to the user's address bounced or was
+	// marked as spam, so nothing is sent to it; EmailSuppression says which
+	EmailSuppressed  bool   `json:"emailSuppressed"`
+	EmailSuppression string `json:"emailSuppression,omitempty"`
+	// ProfilePictureUrl is signed for the family unless the picture is public,
+	// and is a default avatar for users without one
+	ProfilePictureUrl string `json:"profilePictureUrl,omitempty"`
+}
+
+// userProfile masks NIN and BVN unless the caller may view identity numbers.
+type userProfile struct {
+	Id                uuid.UUID  `json:"id"`
+	UserID            uuid.UUID  `json:"userId"`
+	FirstName         string     `json:"firstName"`
+	LastName          string     `json:"lastName"`
+	Bio               string     `json:"bio"`
+	NIN               string     `json:"nin,omitempty"`
+	BVN               string     `json:"bvn,omitempty"`
+	DateOfBirth       *time.Time `json:"dateOfBirth"`
+	ProfilePictureUrl string     `json:"profilePictureUrl"`
+	PictureIsPublic   bool       `json:"pictureIsPublic"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+type profileChange struct {
+	Id        uuid.UUID                       `json:"id"`
+	Version   int                             `json:"version"`
+	Action    string                          `json:"action"`
+	ActorID   *uuid.UUID                      `json:"actorId"`
+	Fields    []interfaces.ProfileFieldChange `json:"fields"`
+	ChangedAt time.Time                       `json:"changedAt"`
 }
 
 type role struct {
-	Id          string   `json:"id" binding:"required"`
-	Permissions []string `json:"permissions" binding:"required"`
+	Id          string             `json:"id" binding:"required"`
+	Name        string             `json:"name,omitempty"`
+	GroupID     *uuid.UUID         `json:"groupId,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Inherits    []string           `json:"inherits,omitempty"`
+	Permissions []string           `json:"permissions" binding:"required"`
+	Conditions  []policy.Condition `json:"conditions,omitempty"`
+}
+
+type grant struct {
+	Id           uuid.UUID          `json:"id"`
+	PermissionID string             `json:"permissionId"`
+	StartsAt     time.Time          `json:"startsAt"`
+	EndsAt       time.Time          `json:"endsAt"`
+	GrantedBy    uuid.UUID          `json:"grantedBy"`
+	RevokedAt    *time.Time         `json:"revokedAt,omitempty"`
+	Conditions   []policy.Condition `json:"conditions,omitempty"`
+}
+
+type createGrantRequest struct {
+	PermissionID string             `json:"permissionId" binding:"required" example:"CanSendtoBank"`
+	StartsAt     *time.Time         `json:"startsAt" example:"2024-08-01T00:00:00Z"`
+	EndsAt       time.Time          `json:"endsAt" binding:"required" example:"2024-08-08T00:00:00Z"`
+	Conditions   []policy.Condition `json:"conditions"`
+}
+
+type permission struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+	MinTier     string `json:"minTier,omitempty"`
+}
+
+type createRoleRequest struct {
+	ID          string             `json:"id" binding:"required"`
+	Description string             `json:"description"`
+	Inherits    []string           `json:"inherits" example:"member"`
+	Permissions []string           `json:"permissions"`
+	Conditions  []policy.Condition `json:"conditions"`
+}
+
+type updateRoleRequest struct {
+	// Description, Permissions and Conditions are left unchanged when omitted
+	Description *string            `json:"description"`
+	Permissions []string           `json:"permissions"`
+	Conditions  []policy.Condition `json:"conditions"`
+}
+
+type createFamilyRoleRequest struct {
+	Name        string             `json:"name" binding:"required,max=50" example:"Teen"`
+	Description string             `json:"description"`
+	Permissions []string           `json:"permissions" binding:"required,min=1" example:"CanSendtoSubAcc"`
+	Conditions  []policy.Condition `json:"conditions"`
+}
+
+type updateFamilyRoleRequest struct {
+	// Description and Conditions are left unchanged when omitted
+	Description *string            `json:"description"`
+	Permissions []string           `json:"permissions" binding:"required,min=1"`
+	Conditions  []policy.Condition `json:"conditions"`
+}
+
+type assignRoleRequest struct {
+	RoleID string `json:"roleId" binding:"required"`
+}
+
+type roleParentsRequest struct {
+	Inherits []string `json:"inherits" binding:"required" example:"member"`
+}
+
+type rolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+type createPermissionRequest struct {
+	ID          string `json:"id" binding:"required"`
+	Description string `json:"description"`
+	MinTier     string `json:"minTier" example:"bvnVerified"`
+}
+
+type updatePermissionRequest struct {
+	Description string `json:"description" binding:"required"`
+	// MinTier is left unchanged when omitted; an empty string removes it
+	MinTier *string `json:"minTier" example:"bvnVerified"`
+}
+
+type outboxEmail struct {
+	Id            uuid.UUID  `json:"id"`
+	To            string     `json:"to"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	LastError     string     `json:"lastError"`
+	SentAt        *time.Time `json:"sentAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type suppression struct {
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type emailFeedbackRequest struct {
+	Type   string `json:"type" binding:"required" example:"bounce"`
+	Email  string `json:"email" binding:"required" example:"user@example.com"`
+	Status string `json:"status" example:"5.1.1"`
+	Detail string `json:"detail" example:"smtp; 550 5.1.1 user unknown"`
+}
+
+type twoFactorRequest struct {
+	Enabled  *bool  `json:"enabled" binding:"required" example:"false"`
+	Password string `json:"password" binding:"required" example:"password"`
+}
+
+type alertSetting struct {
+	Event    string `json:"event"`
+	Critical bool   `json:"critical"`
+	Muted    bool   `json:"muted"`
+}
+
+type muteAlertsRequest struct {
+	Muted []string `json:"muted" binding:"required" example:"newDeviceLogin"`
+}
+
+type kycVerificationRequest struct {
+	IDType string `json:"idType" binding:"required,oneof=nin bvn" example:"nin"`
+	Number string `json:"number" binding:"required,len=11,numeric" example:"12345678901"`
+}
+
+type kycVerification struct {
+	Id        uuid.UUID  `json:"id"`
+	IDType    string     `json:"idType"`
+	Provider  string     `json:"provider"`
+	Status    string     `json:"status"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+}
+
+type kycDocument struct {
+	Id           uuid.UUID     `json:"id"`
+	UserID       uuid.UUID     `json:"userId"`
+	IDType       string        `json:"idType"`
+	DocumentType string        `json:"documentType"`
+	Number       string        `json:"number"`
+	ContentType  string        `json:"contentType"`
+	Status       string        `json:"status"`
+	Reason       string        `json:"reason,omitempty"`
+	ReviewedBy   *uuid.UUID    `json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time    `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	Applicant    *kycApplicant `json:"applicant,omitempty"`
+}
+
+// kycApplicant is what a reviewer compares an ID document against.
+type kycApplicant struct {
+	Email       string     `json:"email"`
+	FirstName   string     `json:"firstName"`
+	LastName    string     `json:"lastName"`
+	DateOfBirth *time.Time `json:"dateOfBirth"`
+}
+
+type rejectKYCDocumentRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"The document is blurred, upload a clearer photo"`
+}
+
+type authorizeRequest struct {
+	Subject authorizeSubject `json:"subject"`
+	Checks  []authorizeCheck `json:"checks" binding:"required,min=1,max=50,dive"`
+}
+
+// authorizeSubject identifies the user a decision is for, by exactly one of
+// their session token or their user ID.
+type authorizeSubject struct {
+	Token  string `json:"token,omitempty"`
+	UserID string `json:"userId,omitempty"`
+}
+
+type authorizeCheck struct {
+	Action   string            `json:"action" binding:"required" example:"CanSendtoBank"`
+	Resource authorizeResource `json:"resource"`
+	Context  authorizeContext  `json:"context"`
+}
+
+// authorizeContext carries the request attributes policy conditions are
+// evaluated against. Conditions whose attributes are missing are returned
+// with the decision for the caller to enforce.
+type authorizeContext struct {
+	// Amount and SpentToday are in kobo; SpentToday excludes Amount
+	Amount     *int64     `json:"amount,omitempty" example:"250000"`
+	SpentToday *int64     `json:"spentToday,omitempty" example:"1000000"`
+	OwnerID    *uuid.UUID `json:"ownerId,omitempty" example:"d38f91b2-dc3b-4f9d-aeb4-7b95c91e9d08"`
+}
+
+type authorizeResource struct {
+	Type string `json:"type,omitempty" binding:"omitempty,oneof=group subAccount" example:"group"`
+	ID   string `json:"id,omitempty" example:"d38f91b2-dc3b-4f9d-aeb4-7b95c91e9d08"`
+}
+
+type authorizeDecision struct {
+	Action   string            `json:"action"`
+	Resource authorizeResource `json:"resource"`
+	Allowed  bool              `json:"allowed"`
+	Reason   string            `json:"reason"`
+	// Conditions attached to the allowing role or grant, for the caller to enforce
+	Conditions []policy.Condition `json:"conditions,omitempty"`
+}
+
+type authorizeResponse struct {
+	StatusCode uint                `json:"statusCode"`
+	Status     string              `json:"status"`
+	Message    string              `json:"message"`
+	SubjectID  *uuid.UUID          `json:"subjectId"`
+	Decisions  []authorizeDecision `json:"decisions"`
 }
@@ -2,22 +2,192 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
+	"github.com/InternPulse/famtrust-backend-auth/internal/images"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/InternPulse/famtrust-backend-auth/internal/storage"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// cleanProfile converts a profile for a response, masking its NIN and BVN
+// unless showIdentity is set.
+func cleanProfile(p *interfaces.UserProfile, showIdentity bool) userProfile {
+	nin, bvn := p.NIN, p.BVN
+	if !showIdentity {
+		nin, bvn = fieldcrypt.Mask(nin), fieldcrypt.Mask(bvn)
+	}
+
+	return userProfile{
+		Id:                p.ID,
+		UserID:            p.UserID,
+		FirstName:         p.FirstName,
+		LastName:          p.LastName,
+		Bio:               p.Bio,
+		NIN:               nin,
+		BVN:               bvn,
+		DateOfBirth:       p.DateOfBirth,
+		ProfilePictureUrl: pictureURL(p, time.Now()),
+		PictureIsPublic:   p.PictureIsPublic,
+		Version:           p.Version,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
+
+// pictureErrors explains why an upload isn't accepted as a profile picture.
+var pictureErrors = map[error]string{
+	images.ErrUnsupportedType: "Profile picture must be a JPEG, PNG or WebP image",
+	images.ErrTooLarge:        "Profile picture dimensions are too large",
+	images.ErrInvalidImage:    "Profile picture could not be read. Check your upload",
+}
+
+// saveProfilePicture checks an uploaded picture, strips its metadata and
+// stores it with its thumbnails under a new random key, so uploads never
+// overwrite each other. It returns the URL the picture is served at, or
+// writes an error and returns false if it can't be stored.
+func (uh *UserHandlers) saveProfilePicture(c *gin.Context, file *multipart.FileHeader) (string, bool) {
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Error parsing profile picture. Check your upload",
+		})
+		return "", false
+	}
+	data, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Error parsing profile picture. Check your upload",
+		})
+		return "", false
+	}
+
+	picture, err := images.Process(data)
+	if err != nil {
+		message, ok := pictureErrors[err]
+		if !ok {
+			log.Printf("Failed to process profile picture: %v", err)
+			message = "Failed to process profile picture"
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    message,
+		})
+		return "", false
+	}
+
+	key := storage.ProfilePicturePrefix + uuid.New().String() + picture.Ext
+	blobs := map[string][]byte{key: picture.Data}
+	for size, thumbnail := range picture.Thumbnails {
+		blobs[storage.ThumbnailKey(key, size)] = thumbnail
+	}
+	for blobKey, blob := range blobs {
+		if err := uh.storage.Put(c.Request.Context(), blobKey, bytes.NewReader(blob), int64(len(blob)), picture.ContentType); err != nil {
+			log.Printf("Failed to store profile picture %s: %v", blobKey, err)
+			uh.removeProfilePicture(c, storage.ProfilePictureURL(key))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "Failed to save profile picture",
+			})
+			return "", false
+		}
+	}
+
+	return storage.ProfilePictureURL(key), true
+}
+
+// removeProfilePicture deletes the stored picture at url, and its
+// thumbnails, once no profile points at it. Pictures uploaded before keys
+// were random may be shared by several profiles, so they are left for the
+// sweeper, as are failed deletes.
+func (uh *UserHandlers) removeProfilePicture(c *gin.Context, url string) {
+	key, ok := storage.ProfilePictureKey(url)
+	if !ok {
+		return
+	}
+	name := strings.TrimPrefix(key, storage.ProfilePicturePrefix)
+	if _, err := uuid.Parse(strings.TrimSuffix(name, path.Ext(name))); err != nil {
+		return
+	}
+
+	keys := []string{key}
+	for _, size := range images.ThumbnailSizes {
+		keys = append(keys, storage.ThumbnailKey(key, size))
+	}
+	for _, key := range keys {
+		if err := uh.storage.Delete(c.Request.Context(), key); err != nil {
+			log.Printf("Failed to delete profile picture %s: %v", key, err)
+		}
+	}
+}
+
+// dateOfBirth parses an optional date of birth, already checked to be
+// YYYY-MM-DD, writing a 400 and returning false if it is in the future.
+func dateOfBirth(c *gin.Context, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	dob, err := time.Parse(time.DateOnly, value)
+	if err != nil || dob.After(time.Now()) {
+		fieldErrors(c, map[string]string{"dateOfBirth": "must be a date in the past in the form YYYY-MM-DD"})
+		return nil, false
+	}
+	return &dob, true
+}
+
+// profileETag is the entity tag of a profile's version.
+func profileETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// identityConflict explains a unique violation on the NIN or BVN blind index.
+func identityConflict(err error) (string, bool) {
+	switch {
+	case strings.Contains(err.Error(), "nin_index"):
+		return "A user with this NIN already exists", true
+	case strings.Contains(err.Error(), "bvn_index"):
+		return "A user with this BVN already exists", true
+	}
+	return "", false
+}
+
 // @Summary		Retrieve User Profile Details
-// @Description	Retrieve User Profile Details
+// @Description	Retrieve User Profile Details. NIN and BVN are masked to their last four digits unless the user has canViewIdentityNumbers. The ETag header is the profile's version, to send as If-Match when patching it.
 // @Tags			User-Profiles
 // @ID				profile
 // @Accept			json
@@ -39,7 +209,7 @@ func (uh *UserHandlers) GetUserProfileByID(c *gin.Context) {
 	}
 
 	// retrieve the user from the database
-	profile, err := uh.models.Users().GetUserProfileByID(UserID.(uuid.UUID))
+	profile, err := uh.models.Users().In(callerScope(c)).GetUserProfileByID(UserID.(uuid.UUID))
 	if err != nil {
 		if strings.Contains(err.Error(), "record not found") {
 			c.JSON(http.StatusNotFound, loginResponse{
@@ -59,13 +229,19 @@ func (uh *UserHandlers) GetUserProfileByID(c *gin.Context) {
 
 	}
 
+	canViewIdentity := false
+	if user, err := uh.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID)); err == nil {
+		canViewIdentity = slices.Contains(uh.GetPermissions(user), string(rbac.CanViewIdentityNumbers))
+	}
+
 	payload := gin.H{
 		"statusCode": http.StatusOK,
 		"status":     "success",
 		"message":    "User profile retrieved successfully",
-		"profile":    profile,
+		"profile":    cleanProfile(profile, canViewIdentity),
 	}
 
+	c.Header("ETag", profileETag(profile.Version))
 	c.JSON(http.StatusOK, payload)
 }
 
@@ -81,278 +257,232 @@ func (uh *UserHandlers) GetUserProfileByID(c *gin.Context) {
 // @Param			bio				formData	string	true	"User's biography"
 // @Param			nin				formData	int		false	"User's National Identification Number"
 // @Param			bvn				formData	int		false	"User's Bank Verification Number"
-// @Param			profilePicture	formData	file	true	"User's profile picture"
+// @Param			dateOfBirth		formData	string	false	"User's date of birth (YYYY-MM-DD), needed for NIN/BVN verification"
+// @Param			profilePicture	formData	file	true	"User's profile picture: a JPEG, PNG or WebP image"
 // @Param			familyGroupName	formData	string	false	"New User's default family group Name"
 // @Param			familyGroupDescription	formData	string	false	"New User's default family group Description"
+// @Param			pictureIsPublic	formData	bool	false	"Let anyone with the picture's URL see it; by default only the user's family can"
 // @Router			/profile/create [post]
 func (uh *UserHandlers) CreateUserProfile(c *gin.Context) {
 
 	var profile interfaces.UserProfile
 
-	switch {
+	// The picture is uploaded with the profile, so it can only be form data
+	if c.ContentType() != binding.MIMEMultipartPOSTForm {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Send the profile as multipart/form-data with a profilePicture",
+		})
+		return
+	}
+
+	var payload createProfileRequest
+	if !bindRequest(c, &payload) {
+		return
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	user, err := uh.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	token, exists := c.Get("token")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured, failed to retrieve token",
+		})
+		return
+	}
+
+	familyGroupName := payload.FamilyGroupName
+	familyGroupDescription := payload.FamilyGroupDescription
+
+	profile.NIN = payload.NIN
+	profile.BVN = payload.BVN
+	if payload.PictureIsPublic != nil {
+		profile.PictureIsPublic = *payload.PictureIsPublic
+	}
+	var ok bool
+	if profile.DateOfBirth, ok = dateOfBirth(c, payload.DateOfBirth); !ok {
+		return
+	}
+
+	// Get profile picture
+	profilePicture, err := c.FormFile("profilePicture")
+	if err != nil {
+		fieldErrors(c, map[string]string{"profilePicture": "is required"})
+		return
+	}
+
+	const maxUploadSize = 16 << 20
+	if profilePicture.Size > maxUploadSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Picture file is too large",
+		})
+		return
+	}
+
+	if user.Role.ID == rbac.RoleAdmin && (familyGroupName == "" || familyGroupDescription == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "New user must create a new Default Family Group. Group name and description are required",
+		})
+		return
 
-	case strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded"):
-	case strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data"):
+	} else if user.Role.ID == rbac.RoleAdmin && (familyGroupName != "" || familyGroupDescription != "") {
+		// 1. Call the family groups endpoint, create a new default group
+		url := "https://core.famtrust.biz/api/v1/family-groups"
+		familyGroup := gin.H{
+			"name":        familyGroupName,
+			"description": familyGroupDescription,
+			"is_default":  true,
+		}
 
-		UserID, exists := c.Get("UserID")
-		if !exists {
+		familyGroupJSON, err := json.Marshal(familyGroup)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, loginResponse{
 				StatusCode: http.StatusInternalServerError,
 				Status:     "error",
-				Message:    "An error occured",
+				Message:    "Failed to parse family group info into JSON",
 			})
 			return
 		}
 
-		user, err := uh.models.Users().GetUserByID(UserID.(uuid.UUID))
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(familyGroupJSON))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, loginResponse{
 				StatusCode: http.StatusInternalServerError,
 				Status:     "error",
-				Message:    "An error occured",
+				Message:    "Failed to create a default family group for user, cannot proceed without",
 			})
 			return
 		}
 
-		token, exists := c.Get("token")
-		if !exists {
+		// Add headers
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.(string)))
+
+		client := &http.Client{}
+		resp, err := client.Do(req)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, loginResponse{
 				StatusCode: http.StatusInternalServerError,
 				Status:     "error",
-				Message:    "An error occured, failed to retrieve token",
+				Message:    "Failed to create a default family group for user, cannot proceed without",
 			})
 			return
 		}
 
-		firstName := c.PostForm("firstName")
-		lastName := c.PostForm("lastName")
-		bio := c.PostForm("bio")
-		ninStr := c.PostForm("nin")
-		bvnStr := c.PostForm("bvn")
+		defer resp.Body.Close()
 
-		familyGroupName := c.PostForm("familyGroupName")
-		familyGroupDescription := c.PostForm("familyGroupDescription")
-
-		if firstName == "" || lastName == "" || bio == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Incomplete required signup Info",
-			})
-			return
+		var familyGroupResp struct {
+			FamilyGroup struct {
+				ID string `json:"id"`
+			} `json:"family_group"`
 		}
 
-		// Parse NIN
-		if ninStr != "" {
-			nin, err := strconv.ParseUint(ninStr, 10, 64)
+		if resp.StatusCode == 201 {
+			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "Invalid value for NIN",
+				c.JSON(http.StatusInternalServerError, loginResponse{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "error",
+					Message:    "Failed to create a default family group for user, cannot proceed without",
 				})
 				return
 			}
-			profile.NIN = uint(nin)
-		}
 
-		// Parse BVN
-		if bvnStr != "" {
-			bvn, err := strconv.ParseUint(bvnStr, 10, 64)
+			err = json.Unmarshal(body, &familyGroupResp)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "Invalid value for BVN",
+				c.JSON(http.StatusInternalServerError, loginResponse{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "error",
+					Message:    "Failed to create a default family group for user, cannot proceed without",
 				})
 				return
 			}
-			profile.BVN = uint(bvn)
-		}
-
-		// Get profile picture
-		profilePicture, err := c.FormFile("profilePicture")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Error parsing profile picture. Check your upload",
-			})
-			return
-		}
-
-		const maxUploadSize = 16 << 20
-		if profilePicture.Size > maxUploadSize {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Picture file is too large",
-			})
-			return
-		}
 
-		// Generate a unique filename
-		filename := filepath.Base(profilePicture.Filename)
-		dst := filepath.Join("images", "profilePics", filename)
-		dstURL := filepath.Join("images", "profile-pic", filename)
-
-		// Save the file
-		if err := c.SaveUploadedFile(profilePicture, dst); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"statusCode": http.StatusInternalServerError,
-				"status":     "error",
-				"message":    "Failed to save profile picture",
-			})
-			return
-		}
+			groupID, err := uuid.Parse(familyGroupResp.FamilyGroup.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, loginResponse{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "error",
+					Message:    fmt.Sprintf("Failed to parse new family group ID, aborting without: %v", err),
+				})
+				return
+			}
 
-		if user.Role.ID == "admin" && (familyGroupName == "" || familyGroupDescription == "") {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "New user must create a new Default Family Group. Group name and description are required",
-			})
-			return
+			// 2. Call the family memberships endpoint, add user to new default group
+			url = "https://core.famtrust.biz/api/v1/family-memberships"
 
-		} else if user.Role.ID == "admin" && (familyGroupName != "" || familyGroupDescription != "") {
-			// 1. Call the family groups endpoint, create a new default group
-			url := "https://core.famtrust.biz/api/v1/family-groups"
-			familyGroup := gin.H{
-				"name":        familyGroupName,
-				"description": familyGroupDescription,
-				"is_default":  true,
+			familyMembership := gin.H{
+				"family_group_id": groupID.String(),
+				"user_id":         user.ID.String(),
 			}
 
-			familyGroupJSON, err := json.Marshal(familyGroup)
+			familyMembershipJSON, err := json.Marshal(familyMembership)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
-					Message:    "Failed to parse family group info into JSON",
+					Message:    "Failed to parse family membership info into JSON",
 				})
 				return
 			}
 
-			req, err := http.NewRequest("POST", url, bytes.NewBuffer(familyGroupJSON))
+			req2, err := http.NewRequest("POST", url, bytes.NewBuffer(familyMembershipJSON))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
-					Message:    "Failed to create a default family group for user, cannot proceed without",
+					Message:    "Could not create request to family memberships endpoint",
 				})
 				return
 			}
 
 			// Add headers
-			req.Header.Add("Content-Type", "application/json")
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.(string)))
+			req2.Header.Add("Content-Type", "application/json")
+			req2.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.(string)))
 
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			resp2, err := client.Do(req2)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
-					Message:    "Failed to create a default family group for user, cannot proceed without",
+					Message:    "Could not make request to family memberships endpoint",
 				})
 				return
 			}
 
-			defer resp.Body.Close()
-
-			var familyGroupResp struct {
-				FamilyGroup struct {
-					ID string `json:"id"`
-				} `json:"family_group"`
-			}
-
-			if resp.StatusCode == 201 {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    "Failed to create a default family group for user, cannot proceed without",
-					})
-					return
-				}
-
-				err = json.Unmarshal(body, &familyGroupResp)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    "Failed to create a default family group for user, cannot proceed without",
-					})
-					return
-				}
-
-				groupID, err := uuid.Parse(familyGroupResp.FamilyGroup.ID)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    fmt.Sprintf("Failed to parse new family group ID, aborting without: %v", err),
-					})
-					return
-				}
-
-				// 2. Call the family memberships endpoint, add user to new default group
-				url = "https://core.famtrust.biz/api/v1/family-memberships"
-
-				familyMembership := gin.H{
-					"family_group_id": groupID.String(),
-					"user_id":         user.ID.String(),
-				}
-
-				familyMembershipJSON, err := json.Marshal(familyMembership)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    "Failed to parse family membership info into JSON",
-					})
-					return
-				}
-
-				req2, err := http.NewRequest("POST", url, bytes.NewBuffer(familyMembershipJSON))
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    "Could not create request to family memberships endpoint",
-					})
-					return
-				}
-
-				// Add headers
-				req2.Header.Add("Content-Type", "application/json")
-				req2.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.(string)))
-
-				resp2, err := client.Do(req2)
+			if resp2.StatusCode == 201 {
+				user.DefaultGroup = groupID
+				err = uh.models.Users().In(callerScope(c)).UpdateUser(user)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, loginResponse{
 						StatusCode: http.StatusInternalServerError,
 						Status:     "error",
-						Message:    "Could not make request to family memberships endpoint",
-					})
-					return
-				}
-
-				if resp2.StatusCode == 201 {
-					user.DefaultGroup = groupID
-					err = uh.models.Users().UpdateUser(user)
-					if err != nil {
-						c.JSON(http.StatusInternalServerError, loginResponse{
-							StatusCode: http.StatusInternalServerError,
-							Status:     "error",
-							Message:    "Failed to update user information with default group id",
-						})
-						return
-					}
-				} else {
-					c.JSON(http.StatusInternalServerError, loginResponse{
-						StatusCode: http.StatusInternalServerError,
-						Status:     "error",
-						Message:    "Error response from family memberships endpoint",
+						Message:    "Failed to update user information with default group id",
 					})
 					return
 				}
@@ -360,33 +490,44 @@ func (uh *UserHandlers) CreateUserProfile(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
-					Message:    "Error response from family group service. Failed to create user default family group",
+					Message:    "Error response from family memberships endpoint",
 				})
 				return
 			}
+		} else {
+			c.JSON(http.StatusInternalServerError, loginResponse{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "error",
+				Message:    "Error response from family group service. Failed to create user default family group",
+			})
+			return
 		}
+	}
 
-		// User values
-		profile.UserID = UserID.(uuid.UUID)
-		profile.FirstName = firstName
-		profile.LastName = lastName
-		profile.Bio = bio
-		profile.ProfilePictureUrl = dstURL
-
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{
-			"statusCode": http.StatusBadRequest,
-			"status":     "error",
-			"message":    "Invalid signup data. Submit valid form-data",
-			"error": gin.H{
-				"error": fmt.Sprintf("You made use of a :%s: header", c.GetHeader("Content-Type")),
-			},
-		})
+	// Store the picture last, so nothing is left behind if the family group can't be created
+	pictureURL, ok := uh.saveProfilePicture(c, profilePicture)
+	if !ok {
 		return
 	}
 
-	err := uh.models.Users().CreateUserProfile(&profile)
+	// User values
+	profile.UserID = UserID.(uuid.UUID)
+	profile.FirstName = payload.FirstName
+	profile.LastName = payload.LastName
+	profile.Bio = payload.Bio
+	profile.ProfilePictureUrl = pictureURL
+
+	err = uh.models.Users().In(callerScope(c)).CreateUserProfile(&profile, profile.UserID)
 	if err != nil {
+		uh.removeProfilePicture(c, profile.ProfilePictureUrl)
+		if message, ok := identityConflict(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"statusCode": http.StatusBadRequest,
+				"status":     "error",
+				"message":    message,
+			})
+			return
+		}
 		if strings.Contains(err.Error(), "duplicate key value") {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"statusCode": http.StatusBadRequest,
@@ -411,10 +552,11 @@ func (uh *UserHandlers) CreateUserProfile(c *gin.Context) {
 }
 
 // @Summary			Update User Profile
-// @Description		Update User Profile
+// @Description		Update User Profile. Send the fields as JSON, or as multipart/form-data to upload a new picture
 // @Tags			User-Profiles
 // @ID				update-profile
 // @Security		BearerAuth
+// @Accept			json
 // @Accept			multipart/form-data
 // @Produce			json
 // @Param			firstName		formData	string	false	"User's first name"
@@ -422,164 +564,418 @@ func (uh *UserHandlers) CreateUserProfile(c *gin.Context) {
 // @Param			bio				formData	string	false	"User's biography"
 // @Param			nin				formData	int		false	"User's National Identification Number"
 // @Param			bvn				formData	int		false	"User's Bank Verification Number"
-// @Param			profilePicture	formData	file	false	"User's profile picture"
+// @Param			dateOfBirth		formData	string	false	"User's date of birth (YYYY-MM-DD), needed for NIN/BVN verification"
+// @Param			locale			formData	string	false	"Language of the user's emails: en, yo, ig, ha or pcm"
+// @Param			pictureIsPublic	formData	bool	false	"Let anyone with the picture's URL see it, or only the user's family"
+// @Param			profilePicture	formData	file	false	"User's profile picture: a JPEG, PNG or WebP image"
 // @Router			/profile/update [put]
 func (uh *UserHandlers) UpdateUserProfile(c *gin.Context) {
 
 	var profile interfaces.UserProfile
 
-	switch {
+	var payload updateProfileRequest
+	if !bindRequest(c, &payload) {
+		return
+	}
+
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
 
-	case strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded"):
-	case strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data"):
+	profile.FirstName = payload.FirstName
+	profile.LastName = payload.LastName
+	profile.Bio = payload.Bio
+	profile.NIN = payload.NIN
+	profile.BVN = payload.BVN
 
-		UserID, exists := c.Get("UserID")
-		if !exists {
-			c.JSON(http.StatusInternalServerError, loginResponse{
-				StatusCode: http.StatusInternalServerError,
-				Status:     "error",
-				Message:    "An error occured",
+	var ok bool
+	if profile.DateOfBirth, ok = dateOfBirth(c, payload.DateOfBirth); !ok {
+		return
+	}
+
+	// Check if a profile picture was submitted
+	if fileHeader, err := c.FormFile("profilePicture"); err == nil && fileHeader != nil {
+		// A file was submitted, process it
+		profilePicture, err := c.FormFile("profilePicture")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"statusCode": http.StatusBadRequest,
+				"status":     "error",
+				"message":    "Error parsing profile picture. Check your upload",
 			})
 			return
 		}
 
-		firstName := c.PostForm("firstName")
-		lastName := c.PostForm("lastName")
-		bio := c.PostForm("bio")
-		ninStr := c.PostForm("nin")
-		bvnStr := c.PostForm("bvn")
+		const maxUploadSize = 16 << 20
+		if profilePicture.Size > maxUploadSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"statusCode": http.StatusBadRequest,
+				"status":     "error",
+				"message":    "Picture file is too large",
+			})
+			return
+		}
 
-		if firstName != "" {
-			profile.FirstName = firstName
+		pictureURL, ok := uh.saveProfilePicture(c, profilePicture)
+		if !ok {
+			return
 		}
 
-		if lastName != "" {
-			profile.LastName = lastName
+		profile.ProfilePictureUrl = pictureURL
+
+	} else if err != http.ErrMissingFile && err != http.ErrNotMultipart {
+		// An error occurred while checking for the file (other than the file being missing, or a JSON body)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"statusCode": http.StatusBadRequest,
+			"status":     "error",
+			"message":    "Error parsing image. Check your upload",
+		})
+		return
+	}
+
+	profile.UserID = UserID.(uuid.UUID)
+
+	// The picture being replaced is deleted once the new one is saved
+	var oldPictureURL string
+	if profile.ProfilePictureUrl != "" {
+		if current, err := uh.models.Users().In(callerScope(c)).GetUserProfileByID(profile.UserID); err == nil {
+			oldPictureURL = current.ProfilePictureUrl
 		}
+	}
 
-		if bio != "" {
-			profile.Bio = bio
+	err := uh.models.Users().In(callerScope(c)).UpdateUserProfile(&profile, profile.UserID)
+	if err != nil {
+		uh.removeProfilePicture(c, profile.ProfilePictureUrl)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "User doesn't have a profile",
+			})
+			return
+		}
+		if message, ok := identityConflict(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"statusCode": http.StatusBadRequest,
+				"status":     "error",
+				"message":    message,
+			})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"statusCode": http.StatusInternalServerError,
+			"status":     "error",
+			"message":    "An error occured, failed to update user profile",
+		})
+		return
+	}
 
-		// Parse NIN
-		if ninStr != "" {
-			nin, err := strconv.ParseUint(ninStr, 10, 64)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "Invalid value for NIN",
-				})
-				return
-			}
-			profile.NIN = uint(nin)
+	if oldPictureURL != "" && oldPictureURL != profile.ProfilePictureUrl {
+		uh.removeProfilePicture(c, oldPictureURL)
+	}
+
+	if payload.PictureIsPublic != nil {
+		if err := uh.models.Users().In(callerScope(c)).SetPictureIsPublic(profile.UserID, *payload.PictureIsPublic, profile.UserID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "An error occured, failed to update picture visibility",
+			})
+			return
 		}
+	}
 
-		// Parse BVN
-		if bvnStr != "" {
-			bvn, err := strconv.ParseUint(bvnStr, 10, 64)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "Invalid value for BVN",
-				})
-				return
-			}
-			profile.BVN = uint(bvn)
+	if payload.Locale != "" {
+		if err := uh.models.Users().In(callerScope(c)).UpdateUser(&interfaces.User{UUIDModel: interfaces.UUIDModel{ID: profile.UserID}, Locale: payload.Locale}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "An error occured, failed to update email language",
+			})
+			return
 		}
+	}
 
-		// Check if a profile picture was submitted
-		if fileHeader, err := c.FormFile("profilePicture"); err == nil && fileHeader != nil {
-			// A file was submitted, process it
-			profilePicture, err := c.FormFile("profilePicture")
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"statusCode": http.StatusBadRequest,
-					"status":     "error",
-					"message":    "Error parsing profile picture. Check your upload",
-				})
+	c.JSON(http.StatusCreated, gin.H{
+		"statusCode": http.StatusCreated,
+		"status":     "success",
+		"message":    "User profile updated successfully",
+	})
+}
+
+// @Summary			Patch User Profile
+// @Description		Change some fields of the user's profile. Fields left out are unchanged; an empty string clears bio, nin, bvn or dateOfBirth. Send the ETag from the profile as If-Match to fail with 412 instead of overwriting someone else's change. The new ETag is returned.
+// @Tags			User-Profiles
+// @ID				patch-profile
+// @Security		BearerAuth
+// @Accept			json
+// @Produce			json
+// @Param			Changes		body		patchProfileRequest	true	"Fields to change"
+// @Param			If-Match	header		string				false	"ETag of the profile the changes are based on"
+// @Failure			400
+// @Failure			401			{object}	loginSampleResponseError401
+// @Failure			404
+// @Failure			412
+// @Failure			500			{object}	loginSampleResponseError500
+// @Success			200			{object}	profileSampleResponse200
+// @Router			/profile [patch]
+func (uh *UserHandlers) PatchUserProfile(c *gin.Context) {
+	UserID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+	userID := UserID.(uuid.UUID)
+
+	var payload patchProfileRequest
+	if !bindRequest(c, &payload) {
+		return
+	}
+	invalid := map[string]string{}
+	if payload.NIN != nil && *payload.NIN != "" && !isIdentityNumber(*payload.NIN) {
+		invalid["nin"] = "must be an 11-digit number"
+	}
+	if payload.BVN != nil && *payload.BVN != "" && !isIdentityNumber(*payload.BVN) {
+		invalid["bvn"] = "must be an 11-digit number"
+	}
+	if len(invalid) > 0 {
+		fieldErrors(c, invalid)
+		return
+	}
+
+	current, err := uh.models.Users().In(callerScope(c)).GetUserProfileByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, loginResponse{
+				StatusCode: http.StatusNotFound,
+				Status:     "error",
+				Message:    "User doesn't have a profile",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
+
+	stale := gin.H{
+		"statusCode": http.StatusPreconditionFailed,
+		"status":     "error",
+		"message":    "Profile has changed since it was read. Fetch it again and retry",
+	}
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && !etagMatches(ifMatch, profileETag(current.Version)) {
+		c.Header("ETag", profileETag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, stale)
+		return
+	}
+
+	// Only the fields named are written, so empty values clear them
+	patch := interfaces.UserProfile{UserID: userID}
+	var fields []string
+	if payload.FirstName != nil {
+		patch.FirstName = *payload.FirstName
+		fields = append(fields, "FirstName")
+	}
+	if payload.LastName != nil {
+		patch.LastName = *payload.LastName
+		fields = append(fields, "LastName")
+	}
+	if payload.Bio != nil {
+		patch.Bio = *payload.Bio
+		fields = append(fields, "Bio")
+	}
+	if payload.NIN != nil {
+		patch.NIN = *payload.NIN
+		fields = append(fields, "NIN")
+	}
+	if payload.BVN != nil {
+		patch.BVN = *payload.BVN
+		fields = append(fields, "BVN")
+	}
+	if payload.DateOfBirth != nil {
+		var ok bool
+		if patch.DateOfBirth, ok = dateOfBirth(c, *payload.DateOfBirth); !ok {
+			return
+		}
+		fields = append(fields, "DateOfBirth")
+	}
+	if payload.PictureIsPublic != nil {
+		patch.PictureIsPublic = *payload.PictureIsPublic
+		fields = append(fields, "PictureIsPublic")
+	}
+
+	if len(fields) > 0 {
+		if err := uh.models.Users().In(callerScope(c)).PatchUserProfile(&patch, fields, current.Version, userID); err != nil {
+			if errors.Is(err, interfaces.ErrStaleProfile) {
+				c.JSON(http.StatusPreconditionFailed, stale)
 				return
 			}
-
-			const maxUploadSize = 16 << 20
-			if profilePicture.Size > maxUploadSize {
+			if message, ok := identityConflict(err); ok {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"statusCode": http.StatusBadRequest,
 					"status":     "error",
-					"message":    "Picture file is too large",
+					"message":    message,
 				})
 				return
 			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "An error occured, failed to update user profile",
+			})
+			return
+		}
+	}
 
-			// Generate a unique filename
-			filename := filepath.Base(profilePicture.Filename)
-			dst := filepath.Join("images", "profilePics", filename)
-			dstURL := filepath.Join("images", "profile-pic", filename)
-
-			// Save the file
-			if err := c.SaveUploadedFile(profilePicture, dst); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-
-			profile.ProfilePictureUrl = dstURL
-
-		} else if err != http.ErrMissingFile {
-			// An error occurred while checking for the file (other than the file being missing)
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
+	if payload.Locale != nil {
+		if err := uh.models.Users().In(callerScope(c)).UpdateUser(&interfaces.User{UUIDModel: interfaces.UUIDModel{ID: userID}, Locale: *payload.Locale}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
 				"status":     "error",
-				"message":    "Error parsing image. Check your upload",
+				"message":    "An error occured, failed to update email language",
 			})
 			return
 		}
+	}
+
+	profile, err := uh.models.Users().In(callerScope(c)).GetUserProfileByID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, loginResponse{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "error",
+			Message:    "An error occured",
+		})
+		return
+	}
 
-		profile.UserID = UserID.(uuid.UUID)
+	canViewIdentity := false
+	if user, err := uh.models.Users().In(callerScope(c)).GetUserByID(userID); err == nil {
+		canViewIdentity = slices.Contains(uh.GetPermissions(user), string(rbac.CanViewIdentityNumbers))
+	}
 
-	default:
+	c.Header("ETag", profileETag(profile.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"status":     "success",
+		"message":    "User profile updated successfully",
+		"profile":    cleanProfile(profile, canViewIdentity),
+	})
+}
+
+// pictureSize reads the optional size query parameter, one of the
+// thumbnail sizes, writing a 400 and returning false if it isn't valid. It
+// returns 0 if no size was asked for.
+func pictureSize(c *gin.Context) (int, bool) {
+	sizeStr := c.Query("size")
+	if sizeStr == "" {
+		return 0, true
+	}
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || !slices.Contains(images.ThumbnailSizes, size) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"statusCode": http.StatusBadRequest,
 			"status":     "error",
-			"message":    "Invalid signup data. Submit valid form-data",
-			"error": gin.H{
-				"error": fmt.Sprintf("You made use of a :%s: header", c.GetHeader("Content-Type")),
-			},
+			"message":    "size must be one of " + strings.Trim(fmt.Sprint(images.ThumbnailSizes), "[]"),
 		})
-		return
+		return 0, false
 	}
+	return size, true
+}
 
-	err := uh.models.Users().UpdateUserProfile(&profile)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"statusCode": http.StatusInternalServerError,
-			"status":     "error",
-			"message":    "An error occured, failed to update user profile",
-		})
+// serveAvatar draws and writes a default avatar. Avatars show nothing but
+// the initials in their URL, so they are public and can be cached for good.
+func serveAvatar(c *gin.Context, userID uuid.UUID, initials, format string, size int) {
+	if size == 0 {
+		size = images.DefaultAvatarSize
+	}
+
+	var data []byte
+	contentType := "image/svg+xml"
+	if format == "png" {
+		var err error
+		if data, err = images.AvatarPNG(userID[:], initials, size); err != nil {
+			log.Printf("Failed to draw avatar for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "An error occured while retrieving the picture",
+			})
+			return
+		}
+		contentType = "image/png"
+	} else {
+		data = images.AvatarSVG(userID[:], initials, size)
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"statusCode": http.StatusCreated,
-		"status":     "success",
-		"message":    "User profile updated successfully",
-	})
+	c.Header("X-Content-Type-Options", "nosniff")
+	// SVGs opened directly mustn't be able to load or run anything
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// servablePictureTypes are the content types profile pictures are served
+// with. Pictures stored before uploads were checked may be anything, and are
+// only served if they are images a browser won't run scripts from.
+var servablePictureTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
 }
 
 // @Summary		Get User Profile Picture
-// @Description	Get User Profile Picture
+// @Description	Get User Profile Picture, or a square thumbnail of it with the size parameter. Private pictures need the signed URL (expires and sig) from the owner's profile or the family's user list; public ones don't. Users without a picture get a default avatar named avatar-<userID>-<initials>.png (or .svg), which is public. Responses carry an ETag and may be cached until the URL expires
 // @Tags			User-Profiles
 // @ID				get-profile-pic
-// @Produce		json
+// @Produce		jpeg
+// @Produce		png
+// @Produce		image/svg+xml
+// @Failure		400
+// @Failure		403
 // @Failure		404
+// @Failure		500
 // @Success		200
+// @Success		304
 // @Param			imageName	path	string	true	"Picture Filename"
+// @Param			size		query	int		false	"Side of a square thumbnail in pixels: 64, 128 or 256"
+// @Param			expires		query	int		false	"Expiry of a signed URL, in Unix seconds"
+// @Param			sig			query	string	false	"Signature of a signed URL"
 // @Router			/images/profile-pic/{imageName} [get]
 func (uh *UserHandlers) GetProfilePicture(c *gin.Context) {
-	imageName := c.Param("imageName")
+	size, ok := pictureSize(c)
+	if !ok {
+		return
+	}
 
-	imagePath := filepath.Join("images", "profilePics", imageName)
+	if userID, initials, format, ok := parseAvatarName(c.Param("imageName")); ok {
+		serveAvatar(c, userID, initials, format, size)
+		return
+	}
 
-	// Check if the file exists and is not a directory
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
+	key, ok := storage.ProfilePictureKey(storage.ProfilePictureURL(c.Param("imageName")))
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{
 			"statusCode": http.StatusNotFound,
 			"status":     "error",
@@ -588,5 +984,93 @@ func (uh *UserHandlers) GetProfilePicture(c *gin.Context) {
 		return
 	}
 
-	c.File(imagePath)
+	var cacheControl string
+	if sig := c.Query("sig"); sig != "" {
+		expires, ok := checkPictureSignature(strings.TrimPrefix(key, storage.ProfilePicturePrefix), c.Query("expires"), sig, time.Now())
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{
+				"statusCode": http.StatusForbidden,
+				"status":     "error",
+				"message":    "Picture link is invalid or has expired",
+			})
+			return
+		}
+		// The signed URL is the credential, so CDNs may keep the picture until it expires
+		cacheControl = fmt.Sprintf("public, max-age=%d", int(time.Until(expires).Seconds()))
+	} else {
+		public, err := uh.models.Users().IsPicturePublic(storage.ProfilePictureURL(key))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"statusCode": http.StatusInternalServerError,
+				"status":     "error",
+				"message":    "An error occured while retrieving the picture",
+			})
+			return
+		}
+		if !public {
+			c.JSON(http.StatusForbidden, gin.H{
+				"statusCode": http.StatusForbidden,
+				"status":     "error",
+				"message":    "Picture is private. Use the signed link from the user's profile",
+			})
+			return
+		}
+		cacheControl = "public, max-age=86400"
+	}
+
+	// Pictures from before thumbnails are served whole
+	keys := []string{key}
+	if size != 0 {
+		keys = []string{storage.ThumbnailKey(key, size), key}
+	}
+
+	var picture io.ReadCloser
+	var info *interfaces.BlobInfo
+	err := interfaces.ErrBlobNotFound
+	for _, key := range keys {
+		picture, info, err = uh.storage.Get(c.Request.Context(), key)
+		if !errors.Is(err, interfaces.ErrBlobNotFound) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, interfaces.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"statusCode": http.StatusNotFound,
+				"status":     "error",
+				"message":    "File does not exist",
+			})
+			return
+		}
+		log.Printf("Failed to read profile picture %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"statusCode": http.StatusInternalServerError,
+			"status":     "error",
+			"message":    "An error occured while retrieving the picture",
+		})
+		return
+	}
+	defer picture.Close()
+
+	if !servablePictureTypes[info.ContentType] {
+		c.JSON(http.StatusNotFound, gin.H{
+			"statusCode": http.StatusNotFound,
+			"status":     "error",
+			"message":    "File does not exist",
+		})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d\n%d", info.Key, info.Size, info.ModTime.UnixNano())))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("Cache-Control", cacheControl)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, picture, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+	})
 }
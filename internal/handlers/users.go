@@ -3,30 +3,134 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/InternPulse/famtrust-backend-auth/internal/jwtmod"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserHandlers struct {
-	models interfaces.Models
-	mailer interfaces.Mailer
+	models  interfaces.Models
+	events  interfaces.AccountEvents
+	storage interfaces.BlobStore
 }
 
-func (uh *UserHandlers) GetPermissions(permissions []interfaces.Permission) []string {
+// GetPermissions returns the user's role permissions merged with their active temporary grants.
+func (uh *UserHandlers) GetPermissions(user *interfaces.User) []string {
+	return effectivePermissions(uh.models, user)
+}
+
+// rolePermissions returns the permissions a user holds through their role,
+// including those inherited from its parent roles. If the hierarchy can't be
+// resolved only the role's own permissions are returned.
+func rolePermissions(models interfaces.Models, user *interfaces.User) []string {
+	list, err := models.Roles().GetEffectivePermissions(user.RoleID)
+	if err == nil {
+		return list
+	}
+
+	log.Printf("Unable to resolve inherited permissions for role %s: %v", user.RoleID, err)
+	return cleanRole(user.Role).Permissions
+}
+
+// roleConditions returns the policy conditions on a user's role and its
+// parents, falling back to the role's own conditions like rolePermissions.
+func roleConditions(models interfaces.Models, user *interfaces.User) []policy.Condition {
+	conds, err := models.Roles().GetEffectiveConditions(user.RoleID)
+	if err == nil {
+		return conds
+	}
+
+	log.Printf("Unable to resolve inherited conditions for role %s: %v", user.RoleID, err)
+	return user.Role.Conditions
+}
+
+// heldPermissions is how a user holds permissions, loaded from their role
+// graph, active grants and KYC tier in one pass.
+type heldPermissions struct {
+	// Role lists the permissions of the role and its parents, whatever the
+	// user's KYC tier, with RoleConditions the conditions on them
+	Role           []string
+	RoleConditions []policy.Condition
+	Grants         []interfaces.PermissionGrant
+	// Sources are the role and each grant with the conditions each carries,
+	// less the permissions Withheld until a higher tier than Tier
+	Sources  []policy.Source
+	Tier     rbac.KYCTier
+	Withheld map[string]rbac.KYCTier
+}
+
+// loadHeldPermissions loads how the user holds permissions. If grants can't
+// be loaded only the role counts.
+func loadHeldPermissions(models interfaces.Models, user *interfaces.User) *heldPermissions {
+	held := &heldPermissions{
+		Role:           rolePermissions(models, user),
+		RoleConditions: roleConditions(models, user),
+	}
+
+	grants, err := models.Grants().GetActiveGrantsByUserID(user.ID, time.Now())
+	if err != nil {
+		log.Printf("Unable to load permission grants for user %s: %v", user.ID, err)
+	}
+	held.Grants = grants
+
+	held.Sources = []policy.Source{{
+		Name:        fmt.Sprintf("role %s", user.RoleID),
+		Permissions: held.Role,
+		Conditions:  held.RoleConditions,
+	}}
+	for _, grant := range grants {
+		held.Sources = append(held.Sources, policy.Source{
+			Name:        "a temporary grant",
+			Permissions: []string{grant.PermissionID},
+			Conditions:  grant.Conditions,
+		})
+	}
+
+	held.Tier = kycTier(models, user)
+	held.Withheld = withheldPermissions(models, held.Tier, sourcePermissions(held.Sources))
+	for i := range held.Sources {
+		held.Sources[i].Permissions = slices.DeleteFunc(slices.Clone(held.Sources[i].Permissions), func(perm string) bool {
+			_, ok := held.Withheld[perm]
+			return ok
+		})
+	}
+	return held
+}
+
+// Permissions merges the role's permissions with the grants', regardless of
+// any conditions on them, less those withheld by the KYC tier.
+func (h *heldPermissions) Permissions() []string {
+	return sourcePermissions(h.Sources)
+}
+
+// effectivePermissions is the Permissions of the user's heldPermissions.
+func effectivePermissions(models interfaces.Models, user *interfaces.User) []string {
+	return loadHeldPermissions(models, user).Permissions()
+}
+
+// sourcePermissions lists every permission held through any of sources.
+func sourcePermissions(sources []policy.Source) []string {
 	var list []string
-	for _, perm := range permissions {
-		list = append(list, perm.ID)
+	for _, source := range sources {
+		for _, perm := range source.Permissions {
+			if !slices.Contains(list, perm) {
+				list = append(list, perm)
+			}
+		}
 	}
 	return list
 }
@@ -36,7 +140,9 @@ func (uh *UserHandlers) GetPermissions(permissions []interfaces.Permission) []st
 // @Tags			User-Authentication
 // @ID				login
 // @Accept			json
+// @Accept			x-www-form-urlencoded
 // @Produce		json
+// @Failure		400
 // @Failure		401			{object}	loginSampleResponseError401
 // @Failure		500			{object}	loginSampleResponseError500
 // @Success		200			{object}	loginSampleResponse200
@@ -45,14 +151,7 @@ func (uh *UserHandlers) GetPermissions(permissions []interfaces.Permission) []st
 // @Router			/login [post]
 func (uh *UserHandlers) Login(c *gin.Context) {
 	var loginPayload loginRequest
-
-	err := c.ShouldBindBodyWithJSON(&loginPayload)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, loginResponse{
-			StatusCode: http.StatusBadRequest,
-			Status:     "error",
-			Message:    "Invalid Credentials",
-		})
+	if !bindRequest(c, &loginPayload) {
 		return
 	}
 
@@ -77,6 +176,15 @@ func (uh *UserHandlers) Login(c *gin.Context) {
 		return
 	}
 
+	if user.IsFrozen {
+		c.JSON(http.StatusForbidden, loginResponse{
+			StatusCode: http.StatusForbidden,
+			Status:     "error",
+			Message:    "Account is frozen. Contact FamTrust support",
+		})
+		return
+	}
+
 	var codeStr string
 
 	if user.Has2FA {
@@ -91,32 +199,20 @@ func (uh *UserHandlers) Login(c *gin.Context) {
 			}
 			// NOTE: Created tokens are invalid once another is created
 			// Only the latest/last created token is used
-			err := uh.models.VerCodes().CreateVerificationCode(&verCode)
+			err := uh.models.VerCodes().CreateVerificationCodeWithEmail(&verCode, func(verCode *interfaces.VerCode) (*interfaces.EmailMsg, error) {
+				// Make 6 digit 2FA code from first and last 3 digits of UUID Token
+				verCodeStr := verCode.ID.String()
+				code := verCodeStr[:3] + verCodeStr[len(verCodeStr)-3:]
+
+				// Queue as email
+				verEmail, err := emails.Render(emails.TwoFactorCode, user.Locale, emails.Data{Code: code})
+				if err != nil {
+					return nil, err
+				}
+				verEmail.To = user.Email
+				return verEmail, nil
+			})
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, loginResponse{
-					StatusCode: http.StatusInternalServerError,
-					Status:     "error",
-					Message:    "An error occured",
-				})
-				return
-			}
-
-			// Make 6 digit 2FA code from first and last 3 digits of UUID Token
-			verCodeStr := verCode.ID.String()
-			code := verCodeStr[:3] + verCodeStr[len(verCodeStr)-3:]
-
-			// Send as email
-			verEmail := interfaces.EmailMsg{
-				Subject: "Your FamTrust 2FA Code",
-				From:    "FamTrust <biz@famtrust.biz>",
-				To:      user.Email,
-				BodyText: fmt.Sprintf("Hello there! \n"+
-					"You've requested a 2FA code to login to your FamTrust account. \n"+
-					"Use the code below to login. \n\n\n"+
-					"\"%s\"", code),
-			}
-
-			if err = uh.mailer.SendMail(&verEmail); err != nil {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
@@ -157,7 +253,7 @@ func (uh *UserHandlers) Login(c *gin.Context) {
 		}
 	}
 
-	token, err := jwtmod.GenerateJWT(user.ID)
+	token, err := jwtmod.GenerateJWT(user.ID, codeStr != "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -174,6 +270,20 @@ func (uh *UserHandlers) Login(c *gin.Context) {
 		}
 	}
 
+	// Alert the user to logins from devices they haven't used before
+	newDevice, err := uh.models.Users().RecordDevice(&interfaces.KnownDevice{
+		UserID:      user.ID,
+		Fingerprint: deviceFingerprint(c),
+		UserAgent:   c.Request.UserAgent(),
+		IPAddress:   c.ClientIP(),
+		LastSeenAt:  time.Now(),
+	})
+	if err != nil {
+		log.Printf("Unable to record login device for user %s: %v", user.ID, err)
+	} else if newDevice {
+		uh.events.Publish(accountEvent(c, interfaces.EventNewDeviceLogin, user.ID))
+	}
+
 	payload := loginResponse{
 		StatusCode: http.StatusOK,
 		Status:     "success",
@@ -185,7 +295,7 @@ func (uh *UserHandlers) Login(c *gin.Context) {
 }
 
 // @Summary		Validate User Login Token
-// @Description	Validate User Login Token
+// @Description	Validate User Login Token. The user's KYC tier is included, along with any permissions their role or grants give that the tier doesn't reach yet.
 // @Tags			User-Authentication
 // @ID				validate
 // @Accept			json
@@ -217,7 +327,7 @@ func (uh *UserHandlers) Validate(c *gin.Context) {
 	}
 
 	// retrieve the user from the database
-	user, err := uh.models.Users().GetUserByID(UserID.(uuid.UUID))
+	user, err := uh.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -227,12 +337,13 @@ func (uh *UserHandlers) Validate(c *gin.Context) {
 		return
 	}
 
-	permissions := uh.GetPermissions(user.Role.Permissions)
+	held := loadHeldPermissions(uh.models, user)
 
 	// user payload
 	role := role{
 		Id:          user.Role.ID,
-		Permissions: permissions,
+		Permissions: held.Permissions(),
+		Conditions:  held.RoleConditions,
 	}
 	userPayload := cleanUserData{
 		Id:           user.ID,
@@ -243,6 +354,28 @@ func (uh *UserHandlers) Validate(c *gin.Context) {
 		IsFrozen:     user.IsFrozen,
 		LastLogin:    user.LastLogin,
 		Role:         role,
+		KYCTier:      held.Tier.String(),
+	}
+	for perm, minTier := range held.Withheld {
+		if userPayload.WithheldPermissions == nil {
+			userPayload.WithheldPermissions = map[string]string{}
+		}
+		userPayload.WithheldPermissions[perm] = minTier.String()
+	}
+
+	// Flag a bouncing address so the client can ask the user to fix it
+	suppression, err := uh.models.Suppressions().GetSuppression(user.Email)
+	if err == nil {
+		userPayload.EmailSuppressed = true
+		userPayload.EmailSuppression = suppression.Reason
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Unable to check email suppression for user %s: %v", user.ID, err)
+	}
+
+	// Active grants are already merged into the role's permissions above;
+	// list them too so clients can show when they end
+	for _, grant := range held.Grants {
+		userPayload.Grants = append(userPayload.Grants, cleanGrant(grant))
 	}
 
 	payload := validateResponse{
@@ -260,80 +393,45 @@ func (uh *UserHandlers) Validate(c *gin.Context) {
 // @Description	Create an Admin/Main User Account
 // @Tags			User-Accounts
 // @ID				signup
+// @Accept			json
+// @Accept			x-www-form-urlencoded
 // @Accept			mpfd
 // @Produce		json
 // @Failure		400
 // @Failure		500	{object}	loginSampleResponseError500
 // @Success		201
-// @Param			email		formData	string	true	"Email of the new user"
-// @Param			password	formData	string	true	"Password of the new user"
-// @Param			has2FA		formData	string	false	"Optional true or false value to set new user 2FA preference"
+// @Param			User	body	signupRequest	true	"The new user; has2FA is optional, and locale (en, yo, ig, ha or pcm) defaults to en"
 // @Router			/signup [post]
 func (uh *UserHandlers) Signup(c *gin.Context) {
-	var user interfaces.User
-
-	switch {
-
-	case strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded"):
-	case strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data"):
-
-		email := c.PostForm("email")
-		password := c.PostForm("password")
-		has2FAStr := c.PostForm("has2FA")
-
-		if email == "" || password == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Incomplete signup Info",
-			})
-			return
-		}
-
-		if has2FAStr != "" {
-			has2FA, err := strconv.ParseBool(has2FAStr)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "2FA value must be either true or false",
-				})
-				return
-			}
-
-			user.Has2FA = has2FA
-		}
-
-		// Generate user password
-		bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"statusCode": http.StatusInternalServerError,
-				"status":     "error",
-				"message":    "Error parsing user password",
-			})
-			return
-		}
-		passwordHash := string(bytes)
-
-		user.Email = email
-		user.PasswordHash = passwordHash
-		// Set admin as default role ID for user created via /signup
-		user.RoleID = "admin"
-		user.LastLogin = time.Now()
+	var payload signupRequest
+	if !bindRequest(c, &payload) {
+		return
+	}
 
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{
-			"statusCode": http.StatusBadRequest,
+	// Generate user password
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(payload.Password), 14)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"statusCode": http.StatusInternalServerError,
 			"status":     "error",
-			"message":    "Invalid signup data. Submit valid form-data",
-			"error": gin.H{
-				"error": fmt.Sprintf("You made use of a :%s: header", c.GetHeader("Content-Type")),
-			},
+			"message":    "Error parsing user password",
 		})
 		return
 	}
 
-	err := uh.models.Users().CreateUser(&user)
+	user := interfaces.User{
+		// Set the ID up front, as the new user is the only one in scope
+		UUIDModel:    interfaces.UUIDModel{ID: uuid.New()},
+		Email:        payload.Email,
+		PasswordHash: string(passwordHash),
+		Has2FA:       payload.Has2FA,
+		Locale:       payload.Locale,
+		// Set admin as default role ID for user created via /signup
+		RoleID:    rbac.RoleAdmin,
+		LastLogin: time.Now(),
+	}
+
+	err = uh.models.Users().In(interfaces.Scope{UserID: user.ID}).CreateUser(&user)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -351,7 +449,7 @@ func (uh *UserHandlers) Signup(c *gin.Context) {
 		return
 	}
 
-	token, err := jwtmod.GenerateJWT(user.ID)
+	token, err := jwtmod.GenerateJWT(user.ID, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -374,28 +472,24 @@ func (uh *UserHandlers) Signup(c *gin.Context) {
 // @Tags			User-Accounts
 // @ID				create-user
 // @Security		BearerAuth
+// @Accept			json
+// @Accept			x-www-form-urlencoded
 // @Accept			mpfd
 // @Produce		json
 // @Failure		400
+// @Failure		403
 // @Failure		500	{object}	loginSampleResponseError500
 // @Success		201
-// @Param			email		formData	string	true	"Email of the new user"
-// @Param			password	formData	string	true	"Password of the new user"
-// @Param			roleID		formData	string	false	"Optional Role ID string for new user. Defaults to 'member' if not specified"
-// @Param			has2FA		formData	string	false	"Optional true or false value to set new user 2FA preference"
+// @Param			User	body	createUserRequest	true	"The new user. roleId (roleID in form data) is a global family role or one of the family's own roles, and defaults to member. has2FA is optional, and locale (en, yo, ig, ha or pcm) defaults to en"
 // @Router			/users [post]
 func (uh *UserHandlers) CreateUser(c *gin.Context) {
-	var user interfaces.User
-
-	userWhoCreatesID, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, loginResponse{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "error",
-			Message:    "An error occured",
-		})
+	// Loaded by RequirePermission(rbac.CanCreateUsers). New users join the
+	// creator's family, so the creator needs one.
+	principal, ok := familyPrincipal(c)
+	if !ok {
 		return
 	}
+	userWhoCreates := principal.User
 
 	token, exists := c.Get("token")
 	if !exists {
@@ -407,96 +501,53 @@ func (uh *UserHandlers) CreateUser(c *gin.Context) {
 		return
 	}
 
-	// validate the user against the database
-	userWhoCreates, err := uh.models.Users().GetUserByID(userWhoCreatesID.(uuid.UUID))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, loginResponse{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "error",
-			Message:    "An error occured, couldn't verify user",
-		})
+	var payload createUserRequest
+	if !bindRequest(c, &payload) {
 		return
 	}
 
-	// Confirm User 'canListUsers'
-	permissions := uh.GetPermissions(userWhoCreates.Role.Permissions)
-	if !slices.Contains(permissions, "canCreateUsers") {
-		c.JSON(http.StatusUnauthorized, loginResponse{
-			StatusCode: http.StatusUnauthorized,
-			Status:     "error",
-			Message:    "User does not have the necessary permission to perfom action",
+	roleID := payload.RoleID
+	if roleID == "" {
+		roleID = rbac.RoleMember
+	}
+
+	// Members can only be given roles their creator could assign them
+	_, reason, err := assignableRole(uh.models, principal, roleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"statusCode": http.StatusInternalServerError,
+			"status":     "error",
+			"message":    "An error occured while retrieving role",
 		})
 		return
 	}
+	if reason != "" {
+		fieldErrors(c, map[string]string{"roleId": reason})
+		return
+	}
 
-	switch {
-
-	case strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded"):
-	case strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data"):
-
-		email := c.PostForm("email")
-		password := c.PostForm("password")
-		has2FAStr := c.PostForm("has2FA")
-		roleID := c.PostForm("roleID")
-
-		if email == "" || password == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Incomplete signup Info",
-			})
-			return
-		}
-
-		if has2FAStr != "" {
-			has2FA, err := strconv.ParseBool(has2FAStr)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status":  "error",
-					"message": "2FA value must be either true or false",
-				})
-				return
-			}
-
-			user.Has2FA = has2FA
-		}
-
-		if roleID != "" {
-			user.RoleID = roleID
-		} else {
-			user.RoleID = "member"
-		}
-
-		// Generate user password
-		bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"statusCode": http.StatusInternalServerError,
-				"status":     "error",
-				"message":    "Error parsing user password",
-			})
-			return
-		}
-		passwordHash := string(bytes)
-
-		user.DefaultGroup = userWhoCreates.DefaultGroup
-		user.Email = email
-		user.PasswordHash = passwordHash
-		user.LastLogin = time.Now()
-
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{
-			"statusCode": http.StatusBadRequest,
+	// Generate user password
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(payload.Password), 14)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"statusCode": http.StatusInternalServerError,
 			"status":     "error",
-			"message":    "Invalid signup data. Submit valid form-data",
-			"error": gin.H{
-				"error": fmt.Sprintf("You made use of a :%s: header", c.GetHeader("Content-Type")),
-			},
+			"message":    "Error parsing user password",
 		})
 		return
 	}
 
-	err = uh.models.Users().CreateUser(&user)
+	user := interfaces.User{
+		Email:        payload.Email,
+		PasswordHash: string(passwordHash),
+		Has2FA:       payload.Has2FA,
+		Locale:       payload.Locale,
+		RoleID:       roleID,
+		DefaultGroup: userWhoCreates.DefaultGroup,
+		LastLogin:    time.Now(),
+	}
+
+	err = uh.models.Users().In(callerScope(c)).CreateUser(&user)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -582,12 +633,14 @@ func (uh *UserHandlers) CreateUser(c *gin.Context) {
 // @Accept			json
 // @Produce		json
 // @Failure		400
+// @Failure		403
 // @Failure		500	{object}	loginSampleResponseError500
 // @Success		201
 // @Router			/users [get]
 func (uh *UserHandlers) GetUsersByDefaultGroup(c *gin.Context) {
 
-	UserID, exists := c.Get("UserID")
+	// Loaded by RequirePermission(rbac.CanListUsers)
+	principal, exists := principalFrom(c)
 	if !exists {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -596,28 +649,7 @@ func (uh *UserHandlers) GetUsersByDefaultGroup(c *gin.Context) {
 		})
 		return
 	}
-
-	// validate the user against the database
-	user, err := uh.models.Users().GetUserByID(UserID.(uuid.UUID))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, loginResponse{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "error",
-			Message:    "An error occured, couldn't verify user",
-		})
-		return
-	}
-
-	// Confirm User 'canListUsers'
-	permissions := uh.GetPermissions(user.Role.Permissions)
-	if !slices.Contains(permissions, "canListUsers") {
-		c.JSON(http.StatusUnauthorized, loginResponse{
-			StatusCode: http.StatusUnauthorized,
-			Status:     "error",
-			Message:    "User does not have the necessary permissions to perfom action",
-		})
-		return
-	}
+	user := principal.User
 
 	// get the users from the database
 	users, err := uh.models.Users().GetUsersByDefaultGroup(user.DefaultGroup)
@@ -633,20 +665,21 @@ func (uh *UserHandlers) GetUsersByDefaultGroup(c *gin.Context) {
 	var cleanUsers []cleanUserData
 	for _, user := range *users {
 
-		userPerms := uh.GetPermissions(user.Role.Permissions)
+		userPerms := uh.GetPermissions(&user)
 		userRole := role{
 			Id:          user.Role.ID,
 			Permissions: userPerms,
 		}
 		cleanUser := cleanUserData{
-			Id:           user.ID,
-			Email:        user.Email,
-			Has2FA:       user.Has2FA,
-			DefaultGroup: user.DefaultGroup,
-			IsVerified:   user.IsVerified,
-			IsFrozen:     user.IsFrozen,
-			LastLogin:    user.LastLogin,
-			Role:         userRole,
+			Id:                user.ID,
+			Email:             user.Email,
+			Has2FA:            user.Has2FA,
+			DefaultGroup:      user.DefaultGroup,
+			IsVerified:        user.IsVerified,
+			IsFrozen:          user.IsFrozen,
+			LastLogin:         user.LastLogin,
+			Role:              userRole,
+			ProfilePictureUrl: userPictureURL(&user, time.Now()),
 		}
 
 		cleanUsers = append(cleanUsers, cleanUser)
@@ -668,14 +701,15 @@ func (uh *UserHandlers) GetUsersByDefaultGroup(c *gin.Context) {
 // @Accept			json
 // @Produce		json
 // @Failure		400
-// @Failure		401
+// @Failure		403
 // @Failure		500	{object}	loginSampleResponseError500
 // @Success		201
 // @Param			userID		path	string	true	"User ID"
 // @Router			/users/{userID} [get]
 func (uh *UserHandlers) GetUserByDefaultGroup(c *gin.Context) {
 
-	UserID, exists := c.Get("UserID")
+	// Loaded by RequirePermission(rbac.CanListUsers)
+	principal, exists := principalFrom(c)
 	if !exists {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -684,6 +718,7 @@ func (uh *UserHandlers) GetUserByDefaultGroup(c *gin.Context) {
 		})
 		return
 	}
+	user := principal.User
 
 	userToGetStr := c.Param("userID")
 	userToGetID, err := uuid.Parse(userToGetStr)
@@ -696,28 +731,6 @@ func (uh *UserHandlers) GetUserByDefaultGroup(c *gin.Context) {
 		return
 	}
 
-	// Retrieve the Calling user from the database
-	user, err := uh.models.Users().GetUserByID(UserID.(uuid.UUID))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, loginResponse{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "error",
-			Message:    "An error occured, couldn't verify user",
-		})
-		return
-	}
-
-	// Confirm user 'canListUsers'
-	permissions := uh.GetPermissions(user.Role.Permissions)
-	if !slices.Contains(permissions, "canListUsers") {
-		c.JSON(http.StatusUnauthorized, loginResponse{
-			StatusCode: http.StatusUnauthorized,
-			Status:     "error",
-			Message:    "User does not have the necessary permissions to perfom action",
-		})
-		return
-	}
-
 	// get the user from the database
 	userToGet, err := uh.models.Users().GetUserByDefaultGroup(userToGetID, user.DefaultGroup)
 	if err != nil {
@@ -729,20 +742,21 @@ func (uh *UserHandlers) GetUserByDefaultGroup(c *gin.Context) {
 		return
 	}
 
-	userToGetPerms := uh.GetPermissions(userToGet.Role.Permissions)
+	userToGetPerms := uh.GetPermissions(userToGet)
 	userToGetRole := role{
 		Id:          userToGet.Role.ID,
 		Permissions: userToGetPerms,
 	}
 	userToGetPayload := cleanUserData{
-		Id:           userToGet.ID,
-		Email:        userToGet.Email,
-		Has2FA:       userToGet.Has2FA,
-		DefaultGroup: userToGet.DefaultGroup,
-		IsVerified:   userToGet.IsVerified,
-		IsFrozen:     userToGet.IsFrozen,
-		LastLogin:    userToGet.LastLogin,
-		Role:         userToGetRole,
+		Id:                userToGet.ID,
+		Email:             userToGet.Email,
+		Has2FA:            userToGet.Has2FA,
+		DefaultGroup:      userToGet.DefaultGroup,
+		IsVerified:        userToGet.IsVerified,
+		IsFrozen:          userToGet.IsFrozen,
+		LastLogin:         userToGet.LastLogin,
+		Role:              userToGetRole,
+		ProfilePictureUrl: userPictureURL(userToGet, time.Now()),
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -754,23 +768,24 @@ func (uh *UserHandlers) GetUserByDefaultGroup(c *gin.Context) {
 }
 
 // @Summary		Reset User Password
-// @Description	Reset User Password
+// @Description	Reset User Password. With email, emails the user a reset link; with the code from that link, sets the new password from the body
 // @Tags			User-Accounts
 // @ID				reset-password
 // @Security 		BearerAuth
 // @Accept			json
+// @Accept			x-www-form-urlencoded
+// @Accept			mpfd
 // @Produce		json
 // @Failure		400
 // @Failure		500	{object}	loginSampleResponseError500
 // @Success		200
-// @Param			email		query	string	false	"User Email"
-// @Param			code		query	string	false	"Password reset code"
-// @Param			newPassword		formData	string	false	"New user password"
+// @Param			email		query	string					false	"User Email"
+// @Param			code		query	string					false	"Password reset code"
+// @Param			Password	body	resetPasswordRequest	false	"New user password, with code"
 // @Router			/reset-password [post]
 func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 	resetCodeStr := c.Query("code")
 	email := c.Query("email")
-	newPass := c.PostForm("newPassword")
 
 	if resetCodeStr == "" {
 		if email == "" {
@@ -781,7 +796,7 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 			})
 			return
 		} else {
-			user, err := uh.models.Users().GetUserByEmail("")
+			user, err := uh.models.Users().GetUserByEmail(email)
 			if err != nil {
 				c.JSON(http.StatusOK, loginResponse{
 					StatusCode: http.StatusOK,
@@ -796,30 +811,18 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 				Type:   "password",
 			}
 
-			err = uh.models.VerCodes().CreateVerificationCode(&resetCode)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, loginResponse{
-					StatusCode: http.StatusInternalServerError,
-					Status:     "error",
-					Message:    "Failed to create user password reset link",
-				})
-				return
-			}
-
-			resetLink := "https://" + c.Request.Host + c.Request.URL.Path + "/reset-password/reset" + "?code=" + resetCode.ID.String()
-
-			// Send as email
-			verEmail := interfaces.EmailMsg{
-				Subject: "Reset your password",
-				From:    "FamTrust <biz@famtrust.biz>",
-				To:      user.Email,
-				BodyText: fmt.Sprintf("Hello there! \n"+
-					"You've requested a password reset link for your FamTrust account. \n"+
-					"Click on the link below to reset your password. \n\n\n"+
-					"\"%s\"", resetLink),
-			}
+			err = uh.models.VerCodes().CreateVerificationCodeWithEmail(&resetCode, func(resetCode *interfaces.VerCode) (*interfaces.EmailMsg, error) {
+				resetLink := "https://" + c.Request.Host + c.Request.URL.Path + "/reset-password/reset" + "?code=" + resetCode.ID.String()
 
-			if err = uh.mailer.SendMail(&verEmail); err != nil {
+				// Queue as email
+				verEmail, err := emails.Render(emails.ResetPassword, user.Locale, emails.Data{Link: resetLink})
+				if err != nil {
+					return nil, err
+				}
+				verEmail.To = user.Email
+				return verEmail, nil
+			})
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, loginResponse{
 					StatusCode: http.StatusInternalServerError,
 					Status:     "error",
@@ -837,28 +840,8 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 			}
 		}
 	} else {
-		if newPass == "" {
-			c.JSON(http.StatusBadRequest, loginResponse{
-				StatusCode: http.StatusBadRequest,
-				Status:     "error",
-				Message:    "No new password specified",
-			})
-			return
-		}
-
-		switch {
-
-		case strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded"):
-		case strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data"):
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Invalid data. Submit valid form-data",
-				"error": gin.H{
-					"error": fmt.Sprintf("You made use of a :%s: header", c.GetHeader("Content-Type")),
-				},
-			})
+		var payload resetPasswordRequest
+		if !bindRequest(c, &payload) {
 			return
 		}
 
@@ -883,7 +866,7 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 		}
 
 		// Generate new user password
-		bytes, err := bcrypt.GenerateFromPassword([]byte(newPass), 14)
+		bytes, err := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), 14)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"statusCode": http.StatusInternalServerError,
@@ -894,7 +877,9 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 		}
 		passwordHash := string(bytes)
 
-		user, err := uh.models.Users().GetUserByID(code.UserID)
+		// The reset code stands in for a session, so scope to its user
+		users := uh.models.Users().In(interfaces.Scope{UserID: code.UserID})
+		user, err := users.GetUserByID(code.UserID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"statusCode": http.StatusInternalServerError,
@@ -906,7 +891,7 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 
 		user.PasswordHash = passwordHash
 
-		err = uh.models.Users().UpdateUser(user)
+		err = users.UpdateUser(user)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"statusCode": http.StatusInternalServerError,
@@ -919,6 +904,12 @@ func (uh *UserHandlers) ResetPassword(c *gin.Context) {
 		if err := uh.models.VerCodes().DeleteResetCodeByUserID(code.UserID); err != nil {
 			log.Printf("Failed to delete Email verification code: %v", err)
 		}
+
+		// Sign out sessions opened with the old password
+		if err := users.RevokeSessions(user.ID, time.Now()); err != nil {
+			log.Printf("Failed to revoke sessions of user %s after password reset: %v", user.ID, err)
+		}
+		uh.events.Publish(accountEvent(c, interfaces.EventPasswordChanged, user.ID))
 	}
 
 	c.JSON(http.StatusOK, loginResponse{
@@ -1,19 +1,19 @@
 package handlers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type VerificationHandlers struct {
-	models interfaces.Models
-	mailer interfaces.Mailer
+	models  interfaces.Models
+	kyc     interfaces.KYCProvider
+	storage interfaces.BlobStore
 }
 
 // @Summary		Send User-Email Verification Token
@@ -39,7 +39,7 @@ func (v *VerificationHandlers) VerifyEmail(c *gin.Context) {
 	}
 
 	// retrieve the user from the database
-	user, err := v.models.Users().GetUserByID(UserID.(uuid.UUID))
+	user, err := v.models.Users().In(callerScope(c)).GetUserByID(UserID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
@@ -66,32 +66,20 @@ func (v *VerificationHandlers) VerifyEmail(c *gin.Context) {
 
 		// NOTE: Created tokens are invalid once another is created
 		// Only the latest/last created token is used
-		err := v.models.VerCodes().CreateVerificationCode(&verCode)
+		err := v.models.VerCodes().CreateVerificationCodeWithEmail(&verCode, func(verCode *interfaces.VerCode) (*interfaces.EmailMsg, error) {
+			// create verification link
+			// Note: I hardcoded 'https://'
+			verLink := "https://" + c.Request.Host + c.Request.URL.Path + "/verify" + "?code=" + verCode.ID.String()
+
+			// queue as email
+			verEmail, err := emails.Render(emails.VerifyEmail, user.Locale, emails.Data{Link: verLink})
+			if err != nil {
+				return nil, err
+			}
+			verEmail.To = user.Email
+			return verEmail, nil
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, loginResponse{
-				StatusCode: http.StatusInternalServerError,
-				Status:     "error",
-				Message:    "An error occured",
-			})
-			return
-		}
-
-		// create verification link
-		// Note: I hardcoded 'https://'
-		verLink := "https://" + c.Request.Host + c.Request.URL.Path + "/verify" + "?code=" + verCode.ID.String()
-
-		// send as email
-		verEmail := interfaces.EmailMsg{
-			Subject: "Verify your FamTrust Email",
-			From:    "FamTrust <biz@famtrust.biz>",
-			To:      user.Email,
-			BodyText: fmt.Sprintf("Hello there! \n"+
-				"Welcome to FamTrust. \n"+
-				"Click the link below to verify your Email Address. \n\n\n "+
-				"%s", verLink),
-		}
-
-		if err = v.mailer.SendMail(&verEmail); err != nil {
 			c.JSON(http.StatusInternalServerError, loginResponse{
 				StatusCode: http.StatusInternalServerError,
 				Status:     "error",
@@ -152,7 +140,7 @@ func (v *VerificationHandlers) VerifyEmailToken(c *gin.Context) {
 		return
 	}
 
-	if err = v.models.Users().SetIsVerified(code.UserID, true); err != nil {
+	if err = v.models.Users().In(interfaces.Scope{UserID: code.UserID}).SetIsVerified(code.UserID, true); err != nil {
 		c.JSON(http.StatusInternalServerError, loginResponse{
 			StatusCode: http.StatusInternalServerError,
 			Status:     "error",
@@ -173,7 +161,7 @@ func (v *VerificationHandlers) VerifyEmailToken(c *gin.Context) {
 }
 
 // @Summary		Verify User Signup NIN
-// @Description	Verify User Signup NIN - Currently this is any 10 digit positive integer not currently in use by another user.
+// @Description	Check a signup NIN is 11 digits and not in use by another user. Use /kyc/verifications to verify it against the identity provider.
 // @Tags			Verifications
 // @ID				verify-nin
 // @Produce		json
@@ -185,8 +173,7 @@ func (v *VerificationHandlers) VerifyEmailToken(c *gin.Context) {
 func (v *VerificationHandlers) VerifyNIN(c *gin.Context) {
 	ninStr := c.Query("nin")
 	if ninStr != "" {
-		nin, err := strconv.Atoi(ninStr)
-		if err != nil {
+		if !isIdentityNumber(ninStr) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"statusCode": http.StatusBadRequest,
 				"status":     "error",
@@ -194,15 +181,7 @@ func (v *VerificationHandlers) VerifyNIN(c *gin.Context) {
 			})
 			return
 		}
-		if len(ninStr) != 10 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Invalid NIN",
-			})
-			return
-		}
-		_, err = v.models.Users().GetUserByNIN(nin)
+		_, err := v.models.Users().GetUserByNIN(ninStr)
 		if err != nil {
 			c.JSON(http.StatusOK, gin.H{
 				"statusCode": http.StatusOK,
@@ -229,7 +208,7 @@ func (v *VerificationHandlers) VerifyNIN(c *gin.Context) {
 }
 
 // @Summary		Verify User Signup BVN
-// @Description	Verify User Signup BVN - Currently this is any 10 digit positive integer not currently in use by another user.
+// @Description	Check a signup BVN is 11 digits and not in use by another user. Use /kyc/verifications to verify it against the identity provider.
 // @Tags			Verifications
 // @ID				verify-bvn
 // @Produce		json
@@ -241,16 +220,7 @@ func (v *VerificationHandlers) VerifyNIN(c *gin.Context) {
 func (v *VerificationHandlers) VerifyBVN(c *gin.Context) {
 	bvnStr := c.Query("bvn")
 	if bvnStr != "" {
-		bvn, err := strconv.Atoi(bvnStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"statusCode": http.StatusBadRequest,
-				"status":     "error",
-				"message":    "Invalid BVN",
-			})
-			return
-		}
-		if len(bvnStr) != 10 {
+		if !isIdentityNumber(bvnStr) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"statusCode": http.StatusBadRequest,
 				"status":     "error",
@@ -258,7 +228,7 @@ func (v *VerificationHandlers) VerifyBVN(c *gin.Context) {
 			})
 			return
 		}
-		_, err = v.models.Users().GetUserByBVN(bvn)
+		_, err := v.models.Users().GetUserByBVN(bvnStr)
 		if err != nil {
 			c.JSON(http.StatusOK, gin.H{
 				"statusCode": http.StatusOK,
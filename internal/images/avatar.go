@@ -0,0 +1,171 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultAvatarSize is the side, in pixels, of an avatar asked for without
+// a size.
+const DefaultAvatarSize = 256
+
+// avatarColors are the backgrounds avatars are drawn on, all dark enough
+// for white initials to stay readable.
+var avatarColors = []color.RGBA{
+	{0xC6, 0x28, 0x28, 0xFF}, // red
+	{0xAD, 0x14, 0x57, 0xFF}, // pink
+	{0x6A, 0x1B, 0x9A, 0xFF}, // purple
+	{0x45, 0x27, 0xA0, 0xFF}, // deep purple
+	{0x28, 0x35, 0x93, 0xFF}, // indigo
+	{0x15, 0x65, 0xC0, 0xFF}, // blue
+	{0x02, 0x77, 0xBD, 0xFF}, // light blue
+	{0x00, 0x69, 0x5C, 0xFF}, // teal
+	{0x2E, 0x7D, 0x32, 0xFF}, // green
+	{0xBF, 0x36, 0x0C, 0xFF}, // deep orange
+	{0x4E, 0x34, 0x2E, 0xFF}, // brown
+	{0x37, 0x47, 0x4F, 0xFF}, // blue grey
+}
+
+// avatarFont is the typeface initials are drawn in.
+var avatarFont = sync.OnceValue(func() *opentype.Font {
+	f, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		panic(fmt.Sprintf("images: parsing embedded Go Bold font: %v", err))
+	}
+	return f
+})
+
+// AvatarColor is the background of the avatar for seed, usually a user ID,
+// so a user keeps the same colour whatever their initials.
+func AvatarColor(seed []byte) color.RGBA {
+	h := fnv.New32a()
+	h.Write(seed)
+	return avatarColors[h.Sum32()%uint32(len(avatarColors))]
+}
+
+// Initials are the upper-case first letters of the first and last of
+// names, e.g. "AO" for "Adaeze", "Obi". Accents are dropped, so "Ọlá"
+// gives "O", and letters the avatar font can't draw are left out.
+func Initials(names ...string) string {
+	var words []string
+	for _, name := range names {
+		words = append(words, strings.Fields(name)...)
+	}
+	if len(words) > 2 {
+		words = []string{words[0], words[len(words)-1]}
+	}
+
+	var initials []rune
+	for _, word := range words {
+		if r, ok := initial(word); ok {
+			initials = append(initials, r)
+		}
+	}
+	return string(initials)
+}
+
+// initial is the first letter or digit of word the avatar font can draw.
+func initial(word string) (rune, bool) {
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		r = unicode.ToUpper(r)
+		if !ValidInitial(r) {
+			return 0, false
+		}
+		return r, true
+	}
+	return 0, false
+}
+
+// ValidInitial reports whether r can be drawn as an avatar initial.
+func ValidInitial(r rune) bool {
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return false
+	}
+	var buf sfnt.Buffer
+	index, err := avatarFont().GlyphIndex(&buf, r)
+	return err == nil && index != 0
+}
+
+// AvatarPNG draws initials centred in white on the background for seed, as
+// a size by size PNG.
+func AvatarPNG(seed []byte, initials string, size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(AvatarColor(seed)), image.Point{}, draw.Src)
+
+	if initials != "" {
+		face, err := opentype.NewFace(avatarFont(), &opentype.FaceOptions{
+			Size:    float64(size) * avatarTextScale(initials),
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer face.Close()
+
+		// Centre the ink of the initials rather than their advance box, so
+		// letters without descenders don't sit high
+		bounds, _ := font.BoundString(face, initials)
+		width, height := bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.White,
+			Face: face,
+			Dot: fixed.Point26_6{
+				X: (fixed.I(size)-width)/2 - bounds.Min.X,
+				Y: (fixed.I(size)-height)/2 - bounds.Min.Y,
+			},
+		}
+		d.DrawString(initials)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AvatarSVG is the same avatar as AvatarPNG, as an SVG.
+func AvatarSVG(seed []byte, initials string, size int) []byte {
+	c := AvatarColor(seed)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 100 100">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="100" height="100" fill="#%02X%02X%02X"/>`, c.R, c.G, c.B)
+	if initials != "" {
+		fmt.Fprintf(&buf, `<text x="50" y="50" dy="0.35em" text-anchor="middle" fill="#FFFFFF" font-family="Go, Helvetica, Arial, sans-serif" font-weight="bold" font-size="%.0f">%s</text>`,
+			100*avatarTextScale(initials), html.EscapeString(initials))
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// avatarTextScale is the font size of initials as a fraction of the avatar.
+func avatarTextScale(initials string) float64 {
+	if len([]rune(initials)) > 1 {
+		return 0.4
+	}
+	return 0.5
+}
@@ -0,0 +1,150 @@
+// Package images checks uploaded pictures and prepares them for storage:
+// only real JPEG, PNG and WebP images are accepted, metadata such as EXIF
+// location is dropped by re-encoding, and square thumbnails are generated.
+// It also draws the default avatars of users without a picture.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxPixels caps an image's decoded size. Larger images are refused
+	// before decoding, so a small file can't expand into gigabytes of pixels.
+	MaxPixels = 40_000_000
+	// MaxSide is the longest side a stored picture keeps; larger ones are
+	// scaled down
+	MaxSide = 2048
+	// jpegQuality is used when re-encoding JPEGs and thumbnails
+	jpegQuality = 85
+)
+
+// ThumbnailSizes are the sides, in pixels, of the square thumbnails made
+// for every picture.
+var ThumbnailSizes = []int{64, 128, 256}
+
+var (
+	ErrUnsupportedType = errors.New("image must be a JPEG, PNG or WebP")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+	ErrInvalidImage    = errors.New("image could not be decoded")
+)
+
+// Picture is an uploaded image ready to store: re-encoded without metadata,
+// with a thumbnail per ThumbnailSizes entry.
+type Picture struct {
+	ContentType string
+	// Ext is the file extension for ContentType, e.g. ".jpg"
+	Ext        string
+	Data       []byte
+	Thumbnails map[int][]byte
+}
+
+// allowedTypes are the content types accepted for upload.
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Process checks that data is an allowed image of acceptable dimensions
+// and prepares it for storage. PNGs stay PNGs; JPEGs and opaque WebPs become
+// JPEGs and WebPs with transparency become PNGs, as WebP can't be encoded.
+func Process(data []byte) (*Picture, error) {
+	// Refuse anything but the allowed formats before any decoder sees it
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if config.Width > MaxPixels/config.Height {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if contentType == "image/jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	img = fit(img, MaxSide)
+
+	picture := &Picture{ContentType: "image/jpeg", Ext: ".jpg", Thumbnails: map[int][]byte{}}
+	if contentType == "image/png" || !opaque(img) {
+		picture.ContentType, picture.Ext = "image/png", ".png"
+	}
+
+	if picture.Data, err = picture.encode(img); err != nil {
+		return nil, err
+	}
+	for _, size := range ThumbnailSizes {
+		if picture.Thumbnails[size], err = picture.encode(thumbnail(img, size)); err != nil {
+			return nil, err
+		}
+	}
+	return picture, nil
+}
+
+// encode writes img in the picture's format. Only pixels are written, so
+// any metadata the upload carried is gone.
+func (p *Picture) encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if p.ContentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return buf.Bytes(), err
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// fit scales img down so neither side is longer than side.
+func fit(img image.Image, side int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= side && h <= side {
+		return img
+	}
+	if w >= h {
+		w, h = side, max(1, h*side/w)
+	} else {
+		w, h = max(1, w*side/h), side
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// thumbnail crops the centre square of img and scales it to size pixels.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, image.Rect(x, y, x+side, y+side), draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account events that users are alerted to. Each is also the name of the
+// alert's email template.
+const (
+	EventNewDeviceLogin    = "newDeviceLogin"
+	EventPasswordChanged   = "passwordChanged"
+	EventTwoFactorDisabled = "twoFactorDisabled"
+	EventRoleChanged       = "roleChanged"
+)
+
+// AlertEvents lists every account event users are alerted to
+var AlertEvents = []string{EventNewDeviceLogin, EventPasswordChanged, EventTwoFactorDisabled, EventRoleChanged}
+
+// CriticalEvents are always alerted to; users can't mute them.
+var CriticalEvents = []string{EventPasswordChanged, EventTwoFactorDisabled}
+
+// IsCriticalEvent reports whether alerts for the event can't be muted.
+func IsCriticalEvent(event string) bool {
+	return slices.Contains(CriticalEvents, event)
+}
+
+// AccountEvent is a security-sensitive change to a user's account.
+type AccountEvent struct {
+	Type      string
+	UserID    uuid.UUID
+	At        time.Time
+	IPAddress string
+	UserAgent string
+	// Role is the new role of a roleChanged event
+	Role string
+}
+
+// AccountEvents receives account events as they happen.
+type AccountEvents interface {
+	Publish(event *AccountEvent)
+}
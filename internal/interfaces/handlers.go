@@ -1,11 +1,22 @@
 package interfaces
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/InternPulse/famtrust-backend-auth/internal/rbac"
+	"github.com/gin-gonic/gin"
+)
 
 type Handlers interface {
 	Users() UserHandlers
 	AuthMiddleware() gin.HandlerFunc
+	RequirePermission(perms ...rbac.Permission) gin.HandlerFunc
+	RequireAnyPermission(perms ...rbac.Permission) gin.HandlerFunc
 	Verifications() VerificationHandlers
+	Roles() RoleHandlers
+	Authz() AuthzHandlers
+	Outbox() OutboxHandlers
+	Security() SecurityHandlers
+	// Inbox is nil unless the mailer captures emails
+	Inbox() InboxHandlers
 }
 
 type UserHandlers interface {
@@ -13,13 +24,16 @@ type UserHandlers interface {
 	CreateUser(c *gin.Context)
 	Login(c *gin.Context)
 	Validate(c *gin.Context)
-	GetPermissions(permissions []Permission) []string
+	GetPermissions(user *User) []string
 	ResetPassword(c *gin.Context)
 
 	// User Profiles
 	GetUserProfileByID(c *gin.Context)
 	CreateUserProfile(c *gin.Context)
 	UpdateUserProfile(c *gin.Context)
+	PatchUserProfile(c *gin.Context)
+	GetProfileHistory(c *gin.Context)
+	GetMemberProfileHistory(c *gin.Context)
 	GetProfilePicture(c *gin.Context)
 
 	// Get Users By...
@@ -32,4 +46,73 @@ type VerificationHandlers interface {
 	VerifyEmailToken(c *gin.Context)
 	VerifyNIN(c *gin.Context)
 	VerifyBVN(c *gin.Context)
+	CreateKYCVerification(c *gin.Context)
+	GetKYCVerifications(c *gin.Context)
+	UploadKYCDocument(c *gin.Context)
+	GetKYCDocuments(c *gin.Context)
+
+	// KYC document review
+	GetKYCReviewQueue(c *gin.Context)
+	GetKYCDocumentFile(c *gin.Context)
+	ApproveKYCDocument(c *gin.Context)
+	RejectKYCDocument(c *gin.Context)
+}
+
+type RoleHandlers interface {
+	// Roles
+	GetAllRoles(c *gin.Context)
+	GetRoleByID(c *gin.Context)
+	CreateRole(c *gin.Context)
+	UpdateRole(c *gin.Context)
+	DeleteRole(c *gin.Context)
+	SetRoleParents(c *gin.Context)
+	AddPermissionsToRole(c *gin.Context)
+	RemovePermissionFromRole(c *gin.Context)
+
+	// Family roles
+	GetFamilyRoles(c *gin.Context)
+	CreateFamilyRole(c *gin.Context)
+	UpdateFamilyRole(c *gin.Context)
+	DeleteFamilyRole(c *gin.Context)
+	AssignUserRole(c *gin.Context)
+
+	// Temporary permission grants
+	GetUserGrants(c *gin.Context)
+	CreateUserGrant(c *gin.Context)
+	RevokeUserGrant(c *gin.Context)
+
+	// Permissions
+	GetAllPermissions(c *gin.Context)
+	CreatePermission(c *gin.Context)
+	UpdatePermission(c *gin.Context)
+	DeletePermission(c *gin.Context)
+}
+
+type AuthzHandlers interface {
+	Authorize(c *gin.Context)
+}
+
+type SecurityHandlers interface {
+	SetTwoFactor(c *gin.Context)
+	GetAlertSettings(c *gin.Context)
+	MuteAlerts(c *gin.Context)
+	GetLockoutPage(c *gin.Context)
+	LockAccount(c *gin.Context)
+}
+
+type InboxHandlers interface {
+	GetInbox(c *gin.Context)
+	GetInboxEmail(c *gin.Context)
+	GetInboxEmailHTML(c *gin.Context)
+}
+
+type OutboxHandlers interface {
+	GetOutboxEmails(c *gin.Context)
+	GetOutboxEmail(c *gin.Context)
+	RetryOutboxEmail(c *gin.Context)
+
+	// Bounces, complaints and the suppression list
+	ReceiveEmailFeedback(c *gin.Context)
+	GetSuppressions(c *gin.Context)
+	DeleteSuppression(c *gin.Context)
 }
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// KYC ID types
+const (
+	KYCTypeNIN = "nin"
+	KYCTypeBVN = "bvn"
+)
+
+// ErrIdentityNotFound is returned by a KYCProvider with no record of a number
+var ErrIdentityNotFound = errors.New("no identity found for that number")
+
+// KYCProvider looks identities up by NIN or BVN with an identity provider.
+type KYCProvider interface {
+	Name() string
+	Lookup(ctx context.Context, req *KYCRequest) (*KYCIdentity, error)
+}
+
+// KYCRequest carries the details the user claims alongside the number, as
+// some providers (and the mock) want them.
+type KYCRequest struct {
+	IDType      string
+	Number      string
+	FirstName   string
+	LastName    string
+	DateOfBirth time.Time
+}
+
+// KYCIdentity is what a provider holds on record for a number.
+type KYCIdentity struct {
+	FirstName   string
+	LastName    string
+	DateOfBirth time.Time
+}
@@ -1,12 +1,46 @@
 package interfaces
 
+import (
+	"errors"
+	"time"
+)
+
 type Mailer interface {
 	SendMail(email *EmailMsg) error
 }
 
+// EmailMsg BodyHTML is optional; when set it is sent as an alternative to BodyText.
 type EmailMsg struct {
-	Subject  string
-	From     string
-	To       string
-	BodyText string
+	Subject  string `json:"subject"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	BodyText string `json:"bodyText"`
+	BodyHTML string `json:"bodyHtml"`
+}
+
+// Inbox is implemented by mailers that keep what they send instead of
+// delivering it, for local development and tests.
+type Inbox interface {
+	// Emails returns the captured emails, most recent first
+	Emails() ([]CapturedEmail, error)
+	// Email returns ErrEmailNotFound if there is no email with the ID
+	Email(id string) (*CapturedEmail, error)
+}
+
+// CapturedEmail is an email kept by an Inbox.
+type CapturedEmail struct {
+	ID     string    `json:"id"`
+	SentAt time.Time `json:"sentAt"`
+	EmailMsg
+}
+
+var ErrEmailNotFound = errors.New("no captured email with that ID")
+
+// ErrAddressSuppressed is returned when sending to an address on the
+// suppression list. Retrying won't help.
+var ErrAddressSuppressed = errors.New("recipient address is suppressed")
+
+// SuppressionList reports whether mail to an address must not be sent.
+type SuppressionList interface {
+	IsSuppressed(email string) (bool, error)
 }
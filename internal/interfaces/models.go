@@ -1,8 +1,10 @@
 package interfaces
 
 import (
+	"errors"
 	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -12,36 +14,84 @@ type Models interface {
 	Roles() UserRoles
 	Permissions() UserPermissions
 	VerCodes() VerCodeModels
+	AuditLogs() AuditLogModels
+	Grants() GrantModels
+	KYC() KYCModels
+	Outbox() OutboxModels
+	Suppressions() SuppressionModels
+}
+
+// Scope is whose rows a UserModels view reaches when row-level security is
+// on: the user's own, and those of their family group.
+type Scope struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
 }
 
 type UserModels interface {
+	// In returns a view of the models limited to scope, normally the caller's.
+	In(scope Scope) UserModels
+	// Unscoped returns a view that reaches every family group, for
+	// platform-admin and system work.
+	Unscoped() UserModels
 	CreateUser(user *User) error
 	GetUserByID(userID uuid.UUID) (*User, error)
 	GetUserByEmail(email string) (*User, error)
-	CreateUserProfile(profile *UserProfile) error
-	UpdateUserProfile(profile *UserProfile) error
+	CreateUserProfile(profile *UserProfile, actorID uuid.UUID) error
+	UpdateUserProfile(profile *UserProfile, actorID uuid.UUID) error
+	PatchUserProfile(profile *UserProfile, fields []string, version int, actorID uuid.UUID) error
 	GetUserProfileByID(userID uuid.UUID) (*UserProfile, error)
+	GetProfileHistory(userID uuid.UUID, beforeVersion int, limit int) ([]ProfileChange, error)
+	GetProfilePictureUrls() ([]string, error)
+	SetPictureIsPublic(userID uuid.UUID, value bool, actorID uuid.UUID) error
+	IsPicturePublic(url string) (bool, error)
 	UpdateUser(user *User) error
 	DeleteUserByID(userID uuid.UUID) error
 	PasswordMatches(passswordHash string, plainText string) (bool, error)
-	GetUserByBVN(bvn int) (*User, error)
-	GetUserByNIN(nin int) (*User, error)
+	GetUserByBVN(bvn string) (*User, error)
+	GetUserByNIN(nin string) (*User, error)
 	SetIsVerified(userID uuid.UUID, value bool) error
+	SetRoleID(userID uuid.UUID, roleID string) error
+	SetHas2FA(userID uuid.UUID, value bool) error
+	SetMutedAlerts(userID uuid.UUID, alerts []string) error
+	RevokeSessions(userID uuid.UUID, at time.Time) error
+	LockAccount(userID uuid.UUID, at time.Time) error
+	GetSessionUser(userID uuid.UUID) (*User, error)
+	RecordDevice(device *KnownDevice) (bool, error)
 	GetUsersByDefaultGroup(groupID uuid.UUID) (*[]User, error)
 	GetUserByDefaultGroup(userID uuid.UUID, groupID uuid.UUID) (*User, error)
 }
 
+// ErrStaleProfile is returned when a profile was changed since the version
+// an update was based on
+var ErrStaleProfile = errors.New("profile has changed since it was read")
+
+// ErrRoleCycle is returned when a role would end up inheriting from itself
+var ErrRoleCycle = errors.New("role inheritance would create a cycle")
+
 type UserRoles interface {
 	GetAllRoles() ([]Role, error)
+	GetGlobalRoles() ([]Role, error)
 	GetRoleByID(roleID string) (*Role, error)
+	GetRolesByGroup(groupID uuid.UUID) ([]Role, error)
+	GetChildRoleIDs(roleID string) ([]string, error)
+	GetEffectivePermissions(roleID string) ([]string, error)
+	GetEffectiveConditions(roleID string) ([]policy.Condition, error)
+	SetRoleParents(roleID string, parentIDs []string) error
 	CreateRole(role *Role) error
 	UpdateRoleByID(role *Role) error
 	DeleteRoleByID(roleID string) error
+	AddPermissionsToRole(roleID string, perms []Permission) error
+	RemovePermissionsFromRole(roleID string, perms []Permission) error
+	CountUsersWithRole(roleID string) (int64, error)
+	GetManifestVersion() (int, error)
+	SetManifestVersion(version int) error
 }
 
 type UserPermissions interface {
 	GetAllPermissions() ([]Permission, error)
 	GetPermission(perm *Permission) (*Permission, error)
+	GetPermissionsByIDs(permIDs []string) ([]Permission, error)
 	CreatePermission(perm *Permission) error
 	UpdatePermission(perm *Permission) error
 	DeletePermission(perm Permission) error
@@ -49,12 +99,58 @@ type UserPermissions interface {
 
 type VerCodeModels interface {
 	CreateVerificationCode(verCode *VerCode) error
+	CreateVerificationCodeWithEmail(verCode *VerCode, compose func(verCode *VerCode) (*EmailMsg, error)) error
 	GetEmailCodeByID(codeID uuid.UUID) (*VerCode, error)
 	Get2FACodeByUserID(codeID uuid.UUID) (*VerCode, error)
 	GetResetCodeByID(codeID uuid.UUID) (*VerCode, error)
 	DeleteEmailCodeByUserID(userID uuid.UUID) error
 	DeleteResetCodeByUserID(userID uuid.UUID) error
 	Delete2FACodeByUserID(userID uuid.UUID) error
+	GetLockoutCodeByID(codeID uuid.UUID) (*VerCode, error)
+	DeleteLockoutCodesByUserID(userID uuid.UUID) error
+}
+
+type KYCModels interface {
+	CreateVerification(verification *KYCVerification) error
+	UpdateVerification(verification *KYCVerification) error
+	GetVerificationsByUserID(userID uuid.UUID) ([]KYCVerification, error)
+	PinVerification(verification *KYCVerification, number string, profile *UserProfile)
+	GetCurrentVerifications(profile *UserProfile) ([]KYCVerification, error)
+	CreateDocument(document *KYCDocument) error
+	GetDocumentByID(documentID uuid.UUID) (*KYCDocument, error)
+	GetDocumentsByUserID(userID uuid.UUID) ([]KYCDocument, error)
+	GetDocumentsByStatus(status string) ([]KYCDocument, error)
+	ReviewDocument(document *KYCDocument) error
+}
+
+type OutboxModels interface {
+	CreateEmail(email *EmailMsg) error
+	ClaimDueEmails(now time.Time, lease time.Duration, limit int) ([]OutboxEmail, error)
+	MarkEmailSent(emailID uuid.UUID, at time.Time) error
+	MarkEmailFailed(email *OutboxEmail) error
+	GetEmailByID(emailID uuid.UUID) (*OutboxEmail, error)
+	GetEmailsByStatus(status string) ([]OutboxEmail, error)
+	RetryEmail(emailID uuid.UUID, at time.Time) error
+}
+
+type SuppressionModels interface {
+	SuppressAddress(suppression *SuppressedAddress) error
+	GetSuppression(email string) (*SuppressedAddress, error)
+	GetSuppressions() ([]SuppressedAddress, error)
+	DeleteSuppression(email string) error
+	IsSuppressed(email string) (bool, error)
+}
+
+type AuditLogModels interface {
+	CreateAuditLogs(entries []AuditLog) error
+}
+
+type GrantModels interface {
+	CreateGrant(grant *PermissionGrant) error
+	GetGrantByID(grantID uuid.UUID) (*PermissionGrant, error)
+	GetGrantsByUserID(userID uuid.UUID) ([]PermissionGrant, error)
+	GetActiveGrantsByUserID(userID uuid.UUID, at time.Time) ([]PermissionGrant, error)
+	RevokeGrant(grantID uuid.UUID, at time.Time) error
 }
 
 // Create uuid model.
@@ -73,51 +169,263 @@ func (u *UUIDModel) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
+// User PhoneVerifiedAt is set by the FamTrust service that confirms phone
+// numbers; this service only reads it when working out the KYC tier.
+// Locale picks the language of the user's emails, and MutedAlerts the
+// non-critical account events they aren't emailed about. Tokens issued at or
+// before SessionsRevokedAt are rejected.
 type User struct {
 	UUIDModel
-	Email        string      `json:"email" gorm:"not null;unique"`
-	PasswordHash string      `json:"_" gorm:"not null"`
-	RoleID       string      `json:"roleId" gorm:"not null"`
-	DefaultGroup uuid.UUID   `json:"defaultGroup"`
-	Has2FA       bool        `json:"has2FA" gorm:"column:has_2fa;not null"`
-	IsVerified   bool        `json:"isVerified" gorm:"not null"`
-	IsFrozen     bool        `json:"isFrozen" gorm:"not null"`
-	LastLogin    time.Time   `json:"lastLogin" gorm:"not null"`
-	Role         Role        `json:"role" gorm:"foreignKey:RoleID;references:ID"`
-	UserProfile  UserProfile `json:"userProfile" gorm:"foreignKey:UserID;references:ID;constraints:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Email             string      `json:"email" gorm:"not null;unique"`
+	PasswordHash      string      `json:"_" gorm:"not null"`
+	RoleID            string      `json:"roleId" gorm:"not null"`
+	DefaultGroup      uuid.UUID   `json:"defaultGroup"`
+	Has2FA            bool        `json:"has2FA" gorm:"column:has_2fa;not null"`
+	IsVerified        bool        `json:"isVerified" gorm:"not null"`
+	IsFrozen          bool        `json:"isFrozen" gorm:"not null"`
+	PhoneVerifiedAt   *time.Time  `json:"phoneVerifiedAt"`
+	Locale            string      `json:"locale" gorm:"not null;default:'en'"`
+	MutedAlerts       []string    `json:"mutedAlerts" gorm:"type:jsonb;serializer:json"`
+	SessionsRevokedAt *time.Time  `json:"-"`
+	LastLogin         time.Time   `json:"lastLogin" gorm:"not null"`
+	Role              Role        `json:"role" gorm:"foreignKey:RoleID;references:ID"`
+	UserProfile       UserProfile `json:"userProfile" gorm:"foreignKey:UserID;references:ID;constraints:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 type UserProfile struct {
 	UUIDModel
-	UserID            uuid.UUID `json:"userId"`
-	FirstName         string    `json:"firstName" gorm:"not null"`
-	LastName          string    `json:"lastName" gorm:"not null"`
-	Bio               string    `json:"bio" gorm:"not null"`
-	NIN               uint      `json:"nin" gorm:"not null"`
-	BVN               uint      `json:"bvn" gorm:"not null"`
-	ProfilePictureUrl string    `json:"profilePictureUrl" gorm:"not null"`
+	UserID            uuid.UUID  `json:"userId"`
+	FirstName         string     `json:"firstName" gorm:"not null"`
+	LastName          string     `json:"lastName" gorm:"not null"`
+	Bio               string     `json:"bio" gorm:"not null"`
+	DateOfBirth       *time.Time `json:"dateOfBirth" gorm:"type:date"`
+	ProfilePictureUrl string     `json:"profilePictureUrl" gorm:"not null;index"`
+	// PictureIsPublic lets anyone with the picture's URL fetch it; other
+	// pictures are only served through signed URLs given to the family
+	PictureIsPublic bool `json:"pictureIsPublic" gorm:"not null;default:false"`
+	// Version counts changes to the profile, for optimistic concurrency
+	Version int `json:"version" gorm:"not null;default:1"`
+
+	// NIN and BVN hold the plaintext in memory only. The database stores them
+	// encrypted, with the ID of the wrapping key and a blind index that keeps
+	// them unique and searchable.
+	NIN           string  `json:"-" gorm:"-"`
+	BVN           string  `json:"-" gorm:"-"`
+	NINCiphertext string  `json:"-"`
+	NINKeyID      string  `json:"-"`
+	NINIndex      *string `json:"-" gorm:"uniqueIndex"`
+	BVNCiphertext string  `json:"-"`
+	BVNKeyID      string  `json:"-"`
+	BVNIndex      *string `json:"-" gorm:"uniqueIndex"`
 }
 
+// Role GroupID is nil for global roles; family roles carry their group's ID
+// and an ID of the form '<groupID>:<slug>' so they can't collide across families.
 type Role struct {
-	ID          string         `json:"Id" gorm:"primaryKey"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
-	Permissions []Permission   `json:"permissions" gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID          string             `json:"Id" gorm:"primaryKey"`
+	Name        string             `json:"name"`
+	GroupID     *uuid.UUID         `json:"groupId" gorm:"type:uuid;index"`
+	Description string             `json:"description"`
+	Conditions  []policy.Condition `json:"conditions" gorm:"type:jsonb;serializer:json"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt     `gorm:"index"`
+	Permissions []Permission       `json:"permissions" gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Parents     []Role             `json:"parents" gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
+// Permission MinTier names the KYC tier a user needs before the permission
+// takes effect; empty means no minimum.
 type Permission struct {
-	ID        string `json:"Id" gorm:"primaryKey"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
-	Roles     []Role         `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID          string `json:"Id" gorm:"primaryKey"`
+	Description string `json:"description"`
+	MinTier     string `json:"minTier" gorm:"not null;default:''"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Roles       []Role         `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
-// VerCode Type can either be 'email' or '2fa' or 'password'
+// RBACManifest records which version of the embedded roles manifest was last applied
+type RBACManifest struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time
+}
+
+// VerCode Type can either be 'email' or '2fa' or 'password', or 'lockout'
+// for the "this wasn't me" link in security alerts
 // TODO: Implement enums
 type VerCode struct {
 	UUIDModel
 	UserID uuid.UUID `json:"userId" gorm:"not null"`
 	Type   string    `json:"type" gorm:"not null"`
 }
+
+// ProfileChange records one create or update of a user's profile: the
+// fields it changed and who changed them. Version is the profile's version
+// after the change. ActorID is nil for changes made by the system.
+type ProfileChange struct {
+	UUIDModel
+	UserID  uuid.UUID            `json:"userId" gorm:"type:uuid;not null;index:idx_profile_changes_user_version"`
+	Version int                  `json:"version" gorm:"not null;index:idx_profile_changes_user_version"`
+	Action  string               `json:"action" gorm:"not null"`
+	ActorID *uuid.UUID           `json:"actorId" gorm:"type:uuid"`
+	Fields  []ProfileFieldChange `json:"fields" gorm:"type:jsonb;serializer:json"`
+}
+
+// Profile change actions
+const (
+	ProfileCreated = "create"
+	ProfileUpdated = "update"
+)
+
+// ProfileFieldChange is one field's old and new values. Values of encrypted
+// fields are never recorded; Redacted marks that such a field changed.
+type ProfileFieldChange struct {
+	Field    string `json:"field"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// KnownDevice is a device a user has logged in from, so logins from new
+// devices can be alerted to. Fingerprint hashes the client's X-Device-ID
+// header, or its user agent without one.
+type KnownDevice struct {
+	UUIDModel
+	UserID      uuid.UUID `json:"userId" gorm:"type:uuid;not null;uniqueIndex:idx_known_devices_user_fingerprint"`
+	Fingerprint string    `json:"-" gorm:"not null;uniqueIndex:idx_known_devices_user_fingerprint"`
+	UserAgent   string    `json:"userAgent"`
+	IPAddress   string    `json:"ipAddress"`
+	LastSeenAt  time.Time `json:"lastSeenAt" gorm:"not null"`
+}
+
+// KYCVerification Status is one of 'pending', 'verified', 'mismatch' or
+// 'failed'; Reason explains a mismatch or failure.
+type KYCVerification struct {
+	UUIDModel
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	IDType    string     `json:"idType" gorm:"not null"`
+	Provider  string     `json:"provider" gorm:"not null"`
+	Status    string     `json:"status" gorm:"not null"`
+	Reason    string     `json:"reason"`
+	CheckedAt *time.Time `json:"checkedAt"`
+	// NumberIndex and ProfileIndex are blind indexes of the number checked
+	// and of the name and date of birth it matched. A verification only
+	// counts towards the KYC tier while the profile still has both.
+	NumberIndex  string `json:"-"`
+	ProfileIndex string `json:"-"`
+}
+
+// KYC verification statuses
+const (
+	KYCPending  = "pending"
+	KYCVerified = "verified"
+	KYCMismatch = "mismatch"
+	KYCFailed   = "failed"
+)
+
+// KYCDocument is a government ID image uploaded for manual review when the
+// automated NIN/BVN check can't verify the user. StoragePath is the file's
+// key in the blob store, under a prefix no public route serves, and the
+// claimed number is encrypted like profile identity numbers. Status is one of 'pending', 'approved' or
+// 'rejected'; Reason explains a rejection.
+type KYCDocument struct {
+	UUIDModel
+	UserID           uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	IDType           string     `json:"idType" gorm:"not null"`
+	DocumentType     string     `json:"documentType" gorm:"not null"`
+	Number           string     `json:"-" gorm:"-"`
+	NumberCiphertext string     `json:"-" gorm:"not null"`
+	NumberKeyID      string     `json:"-" gorm:"not null"`
+	StoragePath      string     `json:"-" gorm:"not null"`
+	ContentType      string     `json:"contentType" gorm:"not null"`
+	Status           string     `json:"status" gorm:"not null;index"`
+	Reason           string     `json:"reason"`
+	ReviewedBy       *uuid.UUID `json:"reviewedBy" gorm:"type:uuid"`
+	ReviewedAt       *time.Time `json:"reviewedAt"`
+}
+
+// OutboxEmail is an email queued for the outbox worker, written in the same
+// transaction as whatever it announces. Status is one of 'pending', 'sent'
+// or 'dead'; a dead email ran out of attempts and LastError says why. The
+// bodies, which can hold codes and links, are cleared once sent.
+type OutboxEmail struct {
+	UUIDModel
+	To            string     `json:"to" gorm:"not null"`
+	From          string     `json:"from" gorm:"not null"`
+	Subject       string     `json:"subject" gorm:"not null"`
+	BodyText      string     `json:"-" gorm:"not null"`
+	BodyHTML      string     `json:"-"`
+	Status        string     `json:"status" gorm:"not null;index:idx_outbox_emails_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"not null;index:idx_outbox_emails_due,priority:2"`
+	LastError     string     `json:"lastError"`
+	SentAt        *time.Time `json:"sentAt"`
+}
+
+// Outbox email statuses
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailDead    = "dead"
+)
+
+// SuppressedAddress is an email address nothing is sent to, because mail to
+// it hard-bounced or its owner marked our mail as spam. Reason is 'bounce' or
+// 'complaint'; Detail is the DSN status and diagnostic, or the feedback type.
+type SuppressedAddress struct {
+	UUIDModel
+	Email  string `json:"email" gorm:"not null;uniqueIndex"`
+	Reason string `json:"reason" gorm:"not null"`
+	Detail string `json:"detail"`
+}
+
+// Suppression reasons
+const (
+	SuppressedBounce    = "bounce"
+	SuppressedComplaint = "complaint"
+)
+
+// KYC document review statuses
+const (
+	DocumentPending  = "pending"
+	DocumentApproved = "approved"
+	DocumentRejected = "rejected"
+)
+
+// Government ID document types accepted for review
+const (
+	DocumentNINSlip        = "ninSlip"
+	DocumentNationalID     = "nationalIdCard"
+	DocumentPassport       = "passport"
+	DocumentDriversLicence = "driversLicence"
+	DocumentVotersCard     = "votersCard"
+)
+
+// AuditLog records a security-relevant decision or event.
+// Event is a short dotted name such as 'authz.decision'.
+type AuditLog struct {
+	UUIDModel
+	Event        string     `json:"event" gorm:"not null;index"`
+	ActorID      *uuid.UUID `json:"actorId" gorm:"type:uuid;index"`
+	SubjectID    *uuid.UUID `json:"subjectId" gorm:"type:uuid;index"`
+	Action       string     `json:"action"`
+	ResourceType string     `json:"resourceType"`
+	ResourceID   string     `json:"resourceId"`
+	Allowed      bool       `json:"allowed"`
+	Reason       string     `json:"reason"`
+}
+
+// PermissionGrant temporarily gives a user a permission on top of their role,
+// between StartsAt and EndsAt, unless revoked earlier.
+type PermissionGrant struct {
+	UUIDModel
+	UserID       uuid.UUID          `json:"userId" gorm:"type:uuid;not null;index"`
+	PermissionID string             `json:"permissionId" gorm:"not null"`
+	StartsAt     time.Time          `json:"startsAt" gorm:"not null"`
+	EndsAt       time.Time          `json:"endsAt" gorm:"not null;index"`
+	GrantedBy    uuid.UUID          `json:"grantedBy" gorm:"type:uuid;not null"`
+	RevokedAt    *time.Time         `json:"revokedAt"`
+	Conditions   []policy.Condition `json:"conditions" gorm:"type:jsonb;serializer:json"`
+}
@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// BlobStore keeps uploaded files, such as profile pictures, under
+// slash-separated keys like "profilePics/<uuid>.jpg".
+type BlobStore interface {
+	// Put stores size bytes from body under key, replacing any existing blob
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns ErrBlobNotFound if there is no blob under key. The caller
+	// closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	// Delete succeeds if there is no blob under key
+	Delete(ctx context.Context, key string) error
+	// List returns every blob whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+var ErrBlobNotFound = errors.New("blob not found")
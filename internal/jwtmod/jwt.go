@@ -1,6 +1,8 @@
 package jwtmod
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -11,18 +13,24 @@ var JwtKey []byte
 
 type JwtClaim struct {
 	ID uuid.UUID `json:"id" binding:"required"`
+	// TwoFactor is set when the session was signed in with a 2FA code
+	TwoFactor bool `json:"2fa,omitempty"`
 	jwt.StandardClaims
 }
 
-func GenerateJWT(userID uuid.UUID) (string, error) {
+func GenerateJWT(userID uuid.UUID, twoFactor bool) (string, error) {
 	// create expiration time
-	expirationTime := time.Now().Add(24 * time.Hour)
+	now := time.Now()
+	expirationTime := now.Add(24 * time.Hour)
 
 	// user claims payload
 	claims := JwtClaim{
-		ID: userID,
+		ID:        userID,
+		TwoFactor: twoFactor,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
+			// Checked against the user's SessionsRevokedAt
+			IssuedAt: now.Unix(),
 		},
 	}
 
@@ -35,3 +43,22 @@ func GenerateJWT(userID uuid.UUID) (string, error) {
 	return tokenString, nil
 
 }
+
+func ParseJWT(tokenString string) (*JwtClaim, error) {
+	claims := JwtClaim{}
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return JwtKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	return &claims, nil
+}
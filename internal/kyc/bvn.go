@@ -0,0 +1,83 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// BVNProvider looks BVNs up with a NIBSS-style API:
+// POST {BaseURL}/bvn/verify with a bearer API key and a JSON body.
+type BVNProvider struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+type bvnRequest struct {
+	BVN string `json:"bvn"`
+}
+
+type bvnResponse struct {
+	ResponseCode string `json:"responseCode"` // "00" found, "01" not found
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	DateOfBirth  string `json:"dateOfBirth"` // DD-Mon-YYYY
+}
+
+func (b *BVNProvider) Name() string {
+	return "bvn"
+}
+
+func (b *BVNProvider) Lookup(ctx context.Context, req *interfaces.KYCRequest) (*interfaces.KYCIdentity, error) {
+	payload, err := json.Marshal(bvnRequest{BVN: req.Number})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+"/bvn/verify", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+b.APIKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bvn: unexpected status %d", resp.StatusCode)
+	}
+
+	var body bvnResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("bvn: decode response: %w", err)
+	}
+
+	switch body.ResponseCode {
+	case "00":
+	case "01":
+		return nil, interfaces.ErrIdentityNotFound
+	default:
+		return nil, fmt.Errorf("bvn: response code %q", body.ResponseCode)
+	}
+
+	dob, err := time.Parse("02-Jan-2006", body.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("bvn: invalid dateOfBirth %q", body.DateOfBirth)
+	}
+
+	return &interfaces.KYCIdentity{
+		FirstName:   body.FirstName,
+		LastName:    body.LastName,
+		DateOfBirth: dob,
+	}, nil
+}
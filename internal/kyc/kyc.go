@@ -0,0 +1,62 @@
+package kyc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Provider routes NIN lookups to one provider and BVN lookups to another.
+type Provider struct {
+	nin interfaces.KYCProvider
+	bvn interfaces.KYCProvider
+}
+
+// NewProvider builds the provider named by KYC_PROVIDER: "mock" for local
+// development, otherwise the NIMC adapter for NINs and the BVN adapter for BVNs.
+func NewProvider() interfaces.KYCProvider {
+	if os.Getenv("KYC_PROVIDER") == "mock" {
+		mock, err := NewMockProvider(os.Getenv("KYC_MOCK_FILE"), os.Getenv("KYC_MOCK_DEFAULT"))
+		if err != nil {
+			log.Panicf("Unable to load mock KYC provider: %v", err)
+		}
+		return &Provider{nin: mock, bvn: mock}
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	return &Provider{
+		nin: &NIMCProvider{
+			BaseURL: os.Getenv("NIMC_API_URL"),
+			APIKey:  os.Getenv("NIMC_API_KEY"),
+			Client:  client,
+		},
+		bvn: &BVNProvider{
+			BaseURL: os.Getenv("BVN_API_URL"),
+			APIKey:  os.Getenv("BVN_API_KEY"),
+			Client:  client,
+		},
+	}
+}
+
+func (p *Provider) Name() string {
+	if p.nin == p.bvn {
+		return p.nin.Name()
+	}
+	return p.nin.Name() + "+" + p.bvn.Name()
+}
+
+func (p *Provider) Lookup(ctx context.Context, req *interfaces.KYCRequest) (*interfaces.KYCIdentity, error) {
+	switch req.IDType {
+	case interfaces.KYCTypeNIN:
+		return p.nin.Lookup(ctx, req)
+	case interfaces.KYCTypeBVN:
+		return p.bvn.Lookup(ctx, req)
+	default:
+		return nil, fmt.Errorf("unsupported ID type %q", req.IDType)
+	}
+}
@@ -0,0 +1,86 @@
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Mock outcomes for numbers not listed in the mock file
+const (
+	MockMatch    = "match"
+	MockMismatch = "mismatch"
+	MockNotFound = "notfound"
+	MockError    = "error"
+)
+
+// MockProvider answers lookups from a JSON file of identities keyed by
+// number, for local development. Other numbers get the default outcome:
+// "match" echoes the claimed details back, "mismatch" alters the name,
+// "notfound" and "error" fail the lookup.
+type MockProvider struct {
+	identities map[string]mockIdentity
+	outcome    string
+}
+
+type mockIdentity struct {
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	DateOfBirth string `json:"dateOfBirth"` // YYYY-MM-DD
+}
+
+func NewMockProvider(path, outcome string) (*MockProvider, error) {
+	if outcome == "" {
+		outcome = MockMatch
+	}
+	switch outcome {
+	case MockMatch, MockMismatch, MockNotFound, MockError:
+	default:
+		return nil, fmt.Errorf("unknown mock outcome %q", outcome)
+	}
+
+	mock := &MockProvider{identities: map[string]mockIdentity{}, outcome: outcome}
+	if path == "" {
+		return mock, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &mock.identities); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return mock, nil
+}
+
+func (m *MockProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockProvider) Lookup(ctx context.Context, req *interfaces.KYCRequest) (*interfaces.KYCIdentity, error) {
+	if identity, ok := m.identities[req.Number]; ok {
+		dob, err := time.Parse("2006-01-02", identity.DateOfBirth)
+		if err != nil {
+			return nil, fmt.Errorf("mock: invalid dateOfBirth %q for %s", identity.DateOfBirth, req.Number)
+		}
+		return &interfaces.KYCIdentity{FirstName: identity.FirstName, LastName: identity.LastName, DateOfBirth: dob}, nil
+	}
+
+	switch m.outcome {
+	case MockMismatch:
+		return &interfaces.KYCIdentity{FirstName: "Mock", LastName: "Mismatch", DateOfBirth: req.DateOfBirth}, nil
+	case MockNotFound:
+		return nil, interfaces.ErrIdentityNotFound
+	case MockError:
+		return nil, errors.New("mock: provider unavailable")
+	default:
+		return &interfaces.KYCIdentity{FirstName: req.FirstName, LastName: req.LastName, DateOfBirth: req.DateOfBirth}, nil
+	}
+}
@@ -0,0 +1,68 @@
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// NIMCProvider looks NINs up with a NIMC-style identity API:
+// GET {BaseURL}/v1/nin/{nin} with an x-api-key header.
+type NIMCProvider struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+type nimcResponse struct {
+	FirstName string `json:"firstname"`
+	Surname   string `json:"surname"`
+	BirthDate string `json:"birthdate"` // YYYY-MM-DD
+}
+
+func (n *NIMCProvider) Name() string {
+	return "nimc"
+}
+
+func (n *NIMCProvider) Lookup(ctx context.Context, req *interfaces.KYCRequest) (*interfaces.KYCIdentity, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, n.BaseURL+"/v1/nin/"+url.PathEscape(req.Number), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-api-key", n.APIKey)
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := n.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, interfaces.ErrIdentityNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nimc: unexpected status %d", resp.StatusCode)
+	}
+
+	var body nimcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("nimc: decode response: %w", err)
+	}
+
+	dob, err := time.Parse("2006-01-02", body.BirthDate)
+	if err != nil {
+		return nil, fmt.Errorf("nimc: invalid birthdate %q", body.BirthDate)
+	}
+
+	return &interfaces.KYCIdentity{
+		FirstName:   body.FirstName,
+		LastName:    body.Surname,
+		DateOfBirth: dob,
+	}, nil
+}
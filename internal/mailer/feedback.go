@@ -0,0 +1,166 @@
+package mailer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Feedback is a bounce or complaint about mail sent to one recipient.
+type Feedback struct {
+	Recipient string
+	// Type is interfaces.SuppressedBounce or interfaces.SuppressedComplaint
+	Type string
+	// Status is the DSN status code of a bounce, such as 5.1.1
+	Status string
+	// Detail is the bounce's diagnostic or the complaint's feedback type
+	Detail string
+}
+
+// Permanent reports whether the recipient should be suppressed: complaints
+// and bounces other than temporary (4.x.x) failures are.
+func (f Feedback) Permanent() bool {
+	return f.Type == interfaces.SuppressedComplaint || !strings.HasPrefix(f.Status, "4")
+}
+
+// ErrNotReport is returned for messages that aren't delivery status or
+// feedback reports.
+var ErrNotReport = errors.New("message is not a delivery status or feedback report")
+
+// ParseReport reads the bounces and complaints in a raw email: a delivery
+// status notification (RFC 3464) or an abuse feedback report (RFC 5965).
+// Delivered, relayed and expanded recipients in a DSN are left out.
+func ParseReport(r io.Reader) ([]Feedback, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" {
+		return nil, ErrNotReport
+	}
+
+	var feedback []Feedback
+	var complaint textproto.MIMEHeader
+	var originalTo string
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		body, err := readBody(part, part.Header.Get("Content-Transfer-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			// The first block is about the message, the rest one recipient each
+			blocks := fieldBlocks(body)
+			for _, block := range blocks[min(1, len(blocks)):] {
+				if bounce, ok := bounceFeedback(block); ok {
+					feedback = append(feedback, bounce)
+				}
+			}
+		case "message/feedback-report":
+			if blocks := fieldBlocks(body); len(blocks) > 0 {
+				complaint = blocks[0]
+			}
+		case "message/rfc822", "text/rfc822-headers":
+			// The complained-about message, whose To is the fallback recipient
+			if original, err := mail.ReadMessage(strings.NewReader(body + "\n\n")); err == nil {
+				originalTo = original.Header.Get("To")
+			}
+		}
+	}
+
+	if complaint != nil {
+		recipient := fieldAddress(complaint.Get("Original-Rcpt-To"))
+		if recipient == "" {
+			recipient = fieldAddress(originalTo)
+		}
+		if recipient != "" {
+			feedback = append(feedback, Feedback{
+				Recipient: recipient,
+				Type:      interfaces.SuppressedComplaint,
+				Detail:    complaint.Get("Feedback-Type"),
+			})
+		}
+	}
+
+	return feedback, nil
+}
+
+// bounceFeedback reads a per-recipient DSN block, reporting false for
+// recipients the mail reached.
+func bounceFeedback(block textproto.MIMEHeader) (Feedback, bool) {
+	action := strings.ToLower(strings.TrimSpace(block.Get("Action")))
+	if action != "failed" && action != "delayed" {
+		return Feedback{}, false
+	}
+
+	recipient := fieldAddress(block.Get("Final-Recipient"))
+	if recipient == "" {
+		recipient = fieldAddress(block.Get("Original-Recipient"))
+	}
+	if recipient == "" {
+		return Feedback{}, false
+	}
+
+	status := strings.TrimSpace(block.Get("Status"))
+	if action == "delayed" && !strings.HasPrefix(status, "4") {
+		// A delay is never final, whatever status it carries
+		status = "4.0.0"
+	}
+
+	return Feedback{
+		Recipient: recipient,
+		Type:      interfaces.SuppressedBounce,
+		Status:    status,
+		Detail:    strings.TrimSpace(block.Get("Diagnostic-Code")),
+	}, true
+}
+
+// fieldBlocks splits a report body into its blank-line-separated header blocks.
+func fieldBlocks(body string) []textproto.MIMEHeader {
+	var blocks []textproto.MIMEHeader
+	for _, raw := range strings.Split(body, "\n\n") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		reader := textproto.NewReader(bufio.NewReader(strings.NewReader(strings.TrimLeft(raw, "\n") + "\n\n")))
+		block, err := reader.ReadMIMEHeader()
+		if err != nil && len(block) == 0 {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// fieldAddress takes the address out of a field like "rfc822; a@example.com"
+// or a header like "Ada <a@example.com>".
+func fieldAddress(value string) string {
+	if _, address, ok := strings.Cut(value, ";"); ok {
+		value = address
+	}
+	value = strings.TrimSpace(value)
+	if addr, err := mail.ParseAddress(value); err == nil {
+		return addr.Address
+	}
+	return strings.Trim(value, "<>")
+}
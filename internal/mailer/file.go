@@ -0,0 +1,168 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+)
+
+// FileMailer writes each email to its own .eml file in a directory instead of
+// sending it. The files open in any mail client.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+func (m *FileMailer) SendMail(email *interfaces.EmailMsg) error {
+	// Timestamped names list in the order they were sent
+	id := time.Now().UTC().Format("20060102T150405.000000000") + "-" + uuid.NewString()[:8]
+	return os.WriteFile(filepath.Join(m.dir, id+".eml"), []byte(newMessage(email).GetMessage()), 0o640)
+}
+
+func (m *FileMailer) Emails() ([]interfaces.CapturedEmail, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := []interfaces.CapturedEmail{}
+	// ReadDir sorts by name, so oldest first
+	for i := len(entries) - 1; i >= 0; i-- {
+		id, ok := strings.CutSuffix(entries[i].Name(), ".eml")
+		if !ok || entries[i].IsDir() {
+			continue
+		}
+		email, err := m.Email(id)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, *email)
+	}
+	return emails, nil
+}
+
+func (m *FileMailer) Email(id string) (*interfaces.CapturedEmail, error) {
+	if id != filepath.Base(id) {
+		return nil, interfaces.ErrEmailNotFound
+	}
+
+	file, err := os.Open(filepath.Join(m.dir, id+".eml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, interfaces.ErrEmailNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	email, err := parseMessage(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s.eml: %w", id, err)
+	}
+	email.ID = id
+	return email, nil
+}
+
+// parseMessage reads back a message written by SendMail.
+func parseMessage(r io.Reader) (*interfaces.CapturedEmail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoder mime.WordDecoder
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	email := &interfaces.CapturedEmail{EmailMsg: interfaces.EmailMsg{
+		Subject: subject,
+		From:    address(msg.Header.Get("From")),
+		To:      address(msg.Header.Get("To")),
+	}}
+	if sentAt, err := msg.Header.Date(); err == nil {
+		email.SentAt = sentAt
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, err := readBody(msg.Body, msg.Header.Get("Content-Transfer-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+		setBody(email, mediaType, body)
+		return email, nil
+	}
+
+	// NextPart undoes quoted-printable encoding itself
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return email, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		body, err := readBody(part, part.Header.Get("Content-Transfer-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		setBody(email, partType, body)
+	}
+}
+
+// address undoes the quoting and brackets added to an address header.
+func address(header string) string {
+	addr, err := mail.ParseAddress(header)
+	if err != nil {
+		return header
+	}
+	if addr.Name == "" {
+		return addr.Address
+	}
+	return addr.Name + " <" + addr.Address + ">"
+}
+
+// readBody decodes a body, with the CRLF line endings MIME requires turned
+// back into LF.
+func readBody(r io.Reader, encoding string) (string, error) {
+	if strings.EqualFold(encoding, "quoted-printable") {
+		r = quotedprintable.NewReader(r)
+	}
+	body, err := io.ReadAll(r)
+	return strings.ReplaceAll(string(body), "\r\n", "\n"), err
+}
+
+func setBody(email *interfaces.CapturedEmail, mediaType, body string) {
+	switch mediaType {
+	case "text/plain":
+		email.BodyText = body
+	case "text/html":
+		email.BodyHTML = body
+	}
+}
@@ -1,7 +1,7 @@
 package mailer
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"strconv"
 	"time"
@@ -10,48 +10,95 @@ import (
 	mail "github.com/xhit/go-simple-mail/v2"
 )
 
-func (m *Mailer) SendMail(email *interfaces.EmailMsg) error {
-	newEmail := mail.NewMSG()
-	newEmail.SetFrom(email.From).
-		AddTo(email.To).
-		SetSubject(email.Subject)
+// SMTPMailer sends email over SMTP, keeping up to poolSize connections open
+// between sends instead of dialling for every email.
+type SMTPMailer struct {
+	server *mail.SMTPServer
+	idle   chan *mail.SMTPClient
+}
+
+// NewSMTPMailer reads the server from SMTP_SERVER, SMTP_PORT (default 587),
+// SMTP_USERNAME, SMTP_PASSWORD, SMTP_ENCRYPTION (starttls, the default, tls
+// or none) and SMTP_POOL_SIZE (default 2).
+func NewSMTPMailer() (*SMTPMailer, error) {
+	server := mail.NewSMTPClient()
+	server.Host = os.Getenv("SMTP_SERVER")
+	server.Username = os.Getenv("SMTP_USERNAME")
+	server.Password = os.Getenv("SMTP_PASSWORD")
+	server.KeepAlive = true
+	server.ConnectTimeout = 30 * time.Second
+	server.SendTimeout = 30 * time.Second
+
+	server.Port = 587
+	if portStr := os.Getenv("SMTP_PORT"); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT %q: %w", portStr, err)
+		}
+		server.Port = port
+	}
 
-	newEmail.SetBody(mail.TextPlain, email.BodyText)
+	switch encryption := os.Getenv("SMTP_ENCRYPTION"); encryption {
+	case "", "starttls":
+		server.Encryption = mail.EncryptionSTARTTLS
+	case "tls":
+		server.Encryption = mail.EncryptionSSLTLS
+	case "none":
+		server.Encryption = mail.EncryptionNone
+	default:
+		return nil, fmt.Errorf("unknown SMTP_ENCRYPTION %q, want starttls, tls or none", encryption)
+	}
+
+	poolSize := 2
+	if sizeStr := os.Getenv("SMTP_POOL_SIZE"); sizeStr != "" {
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid SMTP_POOL_SIZE %q", sizeStr)
+		}
+		poolSize = size
+	}
 
-	server := newSMTPServer()
-	client, err := server.Connect()
+	return &SMTPMailer{server: server, idle: make(chan *mail.SMTPClient, poolSize)}, nil
+}
+
+func (m *SMTPMailer) SendMail(email *interfaces.EmailMsg) error {
+	client, err := m.client()
 	if err != nil {
 		return err
 	}
 
-	if err := newEmail.Send(client); err != nil {
+	if err := newMessage(email).Send(client); err != nil {
+		// The connection may be in any state; don't reuse it
+		client.Close()
 		return err
 	}
 
+	m.release(client)
 	return nil
 }
 
-func newSMTPServer() *mail.SMTPServer {
-	mailer := mail.NewSMTPClient()
-
-	// Get smtp details from env
-	host := os.Getenv("SMTP_SERVER")
-	portStr := os.Getenv("SMTP_PORT")
-	port, err := strconv.ParseInt(portStr, 10, 64)
-	if err != nil {
-		log.Panicf("Env parse error: %v", err)
+// client takes an idle connection that still answers, or dials a new one.
+func (m *SMTPMailer) client() (*mail.SMTPClient, error) {
+	for {
+		select {
+		case client := <-m.idle:
+			if err := client.Noop(); err == nil {
+				return client, nil
+			}
+			// Dropped by the server while idle
+			client.Close()
+		default:
+			return m.server.Connect()
+		}
 	}
-	username := os.Getenv("SMTP_USERNAME")
-	password := os.Getenv("SMTP_PASSWORD")
-
-	mailer.Host = host
-	mailer.Port = int(port)
-	mailer.Username = username
-	mailer.Password = password
-
-	mailer.Encryption = mail.EncryptionSTARTTLS
-	mailer.ConnectTimeout = 30 * time.Second
-	mailer.SendTimeout = 30 * time.Second
+}
 
-	return mailer
+// release returns a connection to the pool, or closes it if the pool is full.
+func (m *SMTPMailer) release(client *mail.SMTPClient) {
+	select {
+	case m.idle <- client:
+	default:
+		client.Quit()
+		client.Close()
+	}
 }
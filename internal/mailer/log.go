@@ -0,0 +1,21 @@
+package mailer
+
+import (
+	"log"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// LogMailer prints emails instead of sending them.
+type LogMailer struct {
+	logger *log.Logger
+}
+
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) SendMail(email *interfaces.EmailMsg) error {
+	m.logger.Printf("Email to %s from %s\nSubject: %s\n\n%s", email.To, email.From, email.Subject, email.BodyText)
+	return nil
+}
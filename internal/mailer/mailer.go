@@ -1,11 +1,51 @@
+// Package mailer sends email through the backend chosen by MAILER: SMTP for
+// real delivery, or a log, file or in-memory mailer for development and tests.
 package mailer
 
 import (
+	"fmt"
+	"log"
+	"os"
+
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	mail "github.com/xhit/go-simple-mail/v2"
 )
 
-type Mailer struct{}
+// NewMailer builds the mailer named by MAILER: "smtp" (the default), "log"
+// to print emails to stdout, "file" to write them as .eml files under
+// MAILER_DIR, or "memory" to keep them in memory.
+func NewMailer() (interfaces.Mailer, error) {
+	switch backend := os.Getenv("MAILER"); backend {
+	case "", "smtp":
+		return NewSMTPMailer()
+	case "log":
+		return NewLogMailer(log.New(os.Stdout, "", log.LstdFlags)), nil
+	case "file":
+		dir := os.Getenv("MAILER_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return NewFileMailer(dir)
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q, want smtp, log, file or memory", backend)
+	}
+}
+
+// newMessage builds the MIME message for an email: plain text with an HTML
+// alternative when the email has one.
+func newMessage(email *interfaces.EmailMsg) *mail.Email {
+	msg := mail.NewMSG()
+	msg.SetFrom(email.From).
+		AddTo(email.To).
+		SetSubject(email.Subject)
+
+	msg.SetBody(mail.TextPlain, email.BodyText)
+	if email.BodyHTML != "" {
+		// multipart/alternative; clients show the last part they support
+		msg.AddAlternative(mail.TextHTML, email.BodyHTML)
+	}
 
-func NewMailer() interfaces.Mailer {
-	return &Mailer{}
+	return msg
 }
@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// MemoryMailer keeps emails in memory instead of sending them, and lets
+// tests look them up.
+type MemoryMailer struct {
+	mu     sync.Mutex
+	emails []interfaces.CapturedEmail
+	nextID int
+	// sent is closed and replaced whenever an email arrives, waking Wait
+	sent chan struct{}
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{sent: make(chan struct{})}
+}
+
+func (m *MemoryMailer) SendMail(email *interfaces.EmailMsg) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	m.emails = append(m.emails, interfaces.CapturedEmail{
+		ID:       strconv.Itoa(m.nextID),
+		SentAt:   time.Now(),
+		EmailMsg: *email,
+	})
+
+	close(m.sent)
+	m.sent = make(chan struct{})
+	return nil
+}
+
+func (m *MemoryMailer) Emails() ([]interfaces.CapturedEmail, error) {
+	return m.Find(func(interfaces.CapturedEmail) bool { return true }), nil
+}
+
+func (m *MemoryMailer) Email(id string) (*interfaces.CapturedEmail, error) {
+	found := m.Find(func(email interfaces.CapturedEmail) bool { return email.ID == id })
+	if len(found) == 0 {
+		return nil, interfaces.ErrEmailNotFound
+	}
+	return &found[0], nil
+}
+
+// Find returns the emails match accepts, most recent first.
+func (m *MemoryMailer) Find(match func(email interfaces.CapturedEmail) bool) []interfaces.CapturedEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := []interfaces.CapturedEmail{}
+	for i := len(m.emails) - 1; i >= 0; i-- {
+		if match(m.emails[i]) {
+			found = append(found, m.emails[i])
+		}
+	}
+	return found
+}
+
+// To returns the emails sent to address, most recent first.
+func (m *MemoryMailer) To(address string) []interfaces.CapturedEmail {
+	return m.Find(func(email interfaces.CapturedEmail) bool { return email.To == address })
+}
+
+// Last returns the most recent email, if any.
+func (m *MemoryMailer) Last() (interfaces.CapturedEmail, bool) {
+	found := m.Find(func(interfaces.CapturedEmail) bool { return true })
+	if len(found) == 0 {
+		return interfaces.CapturedEmail{}, false
+	}
+	return found[0], true
+}
+
+// Wait returns the most recent email match accepts, waiting for one to be
+// sent if there is none yet. Emails are sent in the background, so tests
+// should wait rather than look straight after the request that queues them.
+func (m *MemoryMailer) Wait(ctx context.Context, match func(email interfaces.CapturedEmail) bool) (interfaces.CapturedEmail, error) {
+	for {
+		m.mu.Lock()
+		sent := m.sent
+		m.mu.Unlock()
+
+		if found := m.Find(match); len(found) > 0 {
+			return found[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return interfaces.CapturedEmail{}, ctx.Err()
+		case <-sent:
+		}
+	}
+}
+
+// Reset forgets every captured email.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emails = nil
+}
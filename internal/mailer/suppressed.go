@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// SuppressedMailer refuses to send to addresses on the suppression list,
+// passing everything else to the wrapped mailer.
+type SuppressedMailer struct {
+	interfaces.Mailer
+	list interfaces.SuppressionList
+}
+
+func NewSuppressedMailer(mailer interfaces.Mailer, list interfaces.SuppressionList) *SuppressedMailer {
+	return &SuppressedMailer{Mailer: mailer, list: list}
+}
+
+// SendMail returns interfaces.ErrAddressSuppressed without sending if the
+// recipient is suppressed.
+func (m *SuppressedMailer) SendMail(email *interfaces.EmailMsg) error {
+	suppressed, err := m.list.IsSuppressed(email.To)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		return interfaces.ErrAddressSuppressed
+	}
+	return m.Mailer.SendMail(email)
+}
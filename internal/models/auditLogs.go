@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type AuditLogs struct {
+	DB *gorm.DB
+}
+
+func (a *AuditLogs) CreateAuditLogs(entries []interfaces.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := a.DB.Create(&entries).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PermissionGrants struct {
+	DB *gorm.DB
+}
+
+func (g *PermissionGrants) CreateGrant(grant *interfaces.PermissionGrant) error {
+	if err := g.DB.Create(&grant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *PermissionGrants) GetGrantByID(grantID uuid.UUID) (*interfaces.PermissionGrant, error) {
+	var grant interfaces.PermissionGrant
+	if err := g.DB.Where("id = ?", grantID).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (g *PermissionGrants) GetGrantsByUserID(userID uuid.UUID) ([]interfaces.PermissionGrant, error) {
+	var grants []interfaces.PermissionGrant
+	if err := g.DB.Where("user_id = ?", userID).Order("starts_at DESC").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// GetActiveGrantsByUserID returns grants in effect at the given time; expired,
+// future and revoked grants are left out.
+func (g *PermissionGrants) GetActiveGrantsByUserID(userID uuid.UUID, at time.Time) ([]interfaces.PermissionGrant, error) {
+	var grants []interfaces.PermissionGrant
+	if err := g.DB.Where("user_id = ?", userID).
+		Where("starts_at <= ?", at).
+		Where("ends_at > ?", at).
+		Where("revoked_at IS NULL").
+		Find(&grants).Error; err != nil {
+
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (g *PermissionGrants) RevokeGrant(grantID uuid.UUID, at time.Time) error {
+	if err := g.DB.Model(&interfaces.PermissionGrant{}).Where("id = ?", grantID).Update("revoked_at", at).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KYCVerifications struct {
+	DB      *gorm.DB
+	Keyring *fieldcrypt.Keyring
+}
+
+func (k *KYCVerifications) CreateVerification(verification *interfaces.KYCVerification) error {
+	if err := k.DB.Create(&verification).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (k *KYCVerifications) UpdateVerification(verification *interfaces.KYCVerification) error {
+	if err := k.DB.Model(&interfaces.KYCVerification{}).Where("id = ?", verification.ID).
+		Select("status", "reason", "checked_at", "number_index", "profile_index").
+		Updates(&verification).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (k *KYCVerifications) GetVerificationsByUserID(userID uuid.UUID) ([]interfaces.KYCVerification, error) {
+	var verifications []interfaces.KYCVerification
+	if err := k.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// PinVerification ties a verification to the number checked and the
+// profile details it matched, which GetCurrentVerifications compares
+// against the profile later.
+func (k *KYCVerifications) PinVerification(verification *interfaces.KYCVerification, number string, profile *interfaces.UserProfile) {
+	verification.NumberIndex = k.Keyring.BlindIndex(verification.IDType, number)
+	verification.ProfileIndex = k.profileIndex(profile)
+}
+
+// GetCurrentVerifications returns the profile's passed verifications whose
+// number and name and date of birth are still the profile's own, so editing
+// any of them undoes the verification.
+func (k *KYCVerifications) GetCurrentVerifications(profile *interfaces.UserProfile) ([]interfaces.KYCVerification, error) {
+	var numbers []string
+	if profile.NINIndex != nil {
+		numbers = append(numbers, *profile.NINIndex)
+	}
+	if profile.BVNIndex != nil {
+		numbers = append(numbers, *profile.BVNIndex)
+	}
+	profileIndex := k.profileIndex(profile)
+	if len(numbers) == 0 || profileIndex == "" {
+		return nil, nil
+	}
+
+	// Number indexes are keyed by ID type, so an NIN can't pass for a BVN
+	var verifications []interfaces.KYCVerification
+	if err := k.DB.Where("user_id = ? AND status = ?", profile.UserID, interfaces.KYCVerified).
+		Where("number_index IN ? AND profile_index = ?", numbers, profileIndex).
+		Order("created_at desc").Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// profileIndex is a blind index of the profile's name and date of birth,
+// normalised as they are compared with the identity provider's record.
+func (k *KYCVerifications) profileIndex(profile *interfaces.UserProfile) string {
+	if profile.DateOfBirth == nil {
+		return ""
+	}
+	details := []string{
+		strings.Join(strings.Fields(strings.ToLower(profile.FirstName)), " "),
+		strings.Join(strings.Fields(strings.ToLower(profile.LastName)), " "),
+		profile.DateOfBirth.Format(time.DateOnly),
+	}
+	return k.Keyring.BlindIndex("kycProfile", strings.Join(details, "\n"))
+}
+
+func (k *KYCVerifications) CreateDocument(document *interfaces.KYCDocument) error {
+	sealed, err := k.Keyring.Seal("kycDocument", document.Number)
+	if err != nil {
+		return err
+	}
+	document.NumberCiphertext, document.NumberKeyID = sealed.Ciphertext, sealed.KeyID
+
+	if err := k.DB.Create(&document).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (k *KYCVerifications) GetDocumentByID(documentID uuid.UUID) (*interfaces.KYCDocument, error) {
+	var document interfaces.KYCDocument
+	if err := k.DB.Where("id = ?", documentID).First(&document).Error; err != nil {
+		return nil, err
+	}
+	if err := k.openDocument(&document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (k *KYCVerifications) GetDocumentsByUserID(userID uuid.UUID) ([]interfaces.KYCDocument, error) {
+	var documents []interfaces.KYCDocument
+	if err := k.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&documents).Error; err != nil {
+		return nil, err
+	}
+	for i := range documents {
+		if err := k.openDocument(&documents[i]); err != nil {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
+
+// GetDocumentsByStatus returns documents oldest first, so the review queue
+// is worked in the order users uploaded.
+func (k *KYCVerifications) GetDocumentsByStatus(status string) ([]interfaces.KYCDocument, error) {
+	var documents []interfaces.KYCDocument
+	if err := k.DB.Where("status = ?", status).Order("created_at").Find(&documents).Error; err != nil {
+		return nil, err
+	}
+	for i := range documents {
+		if err := k.openDocument(&documents[i]); err != nil {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
+
+// ReviewDocument records a reviewer's decision, unless another reviewer has
+// already decided.
+func (k *KYCVerifications) ReviewDocument(document *interfaces.KYCDocument) error {
+	result := k.DB.Model(&interfaces.KYCDocument{}).
+		Where("id = ? AND status = ?", document.ID, interfaces.DocumentPending).
+		Select("status", "reason", "reviewed_by", "reviewed_at").
+		Updates(&document)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (k *KYCVerifications) openDocument(document *interfaces.KYCDocument) error {
+	number, err := k.Keyring.Open(document.NumberCiphertext, document.NumberKeyID)
+	if err != nil {
+		return err
+	}
+	document.Number = number
+	return nil
+}
@@ -1,15 +1,23 @@
 package models
 
 import (
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"gorm.io/gorm"
 )
 
 type Models struct {
-	users       interfaces.UserModels
-	roles       interfaces.UserRoles
-	permissions interfaces.UserPermissions
-	verCodes    interfaces.VerCodeModels
+	users        interfaces.UserModels
+	roles        interfaces.UserRoles
+	permissions  interfaces.UserPermissions
+	verCodes     interfaces.VerCodeModels
+	auditLogs    interfaces.AuditLogModels
+	grants       interfaces.GrantModels
+	kyc          interfaces.KYCModels
+	outbox       interfaces.OutboxModels
+	suppressions interfaces.SuppressionModels
 }
 
 func (m *Models) Users() interfaces.UserModels {
@@ -28,11 +36,42 @@ func (m *Models) VerCodes() interfaces.VerCodeModels {
 	return m.verCodes
 }
 
-func NewModel(DB *gorm.DB) interfaces.Models {
+func (m *Models) AuditLogs() interfaces.AuditLogModels {
+	return m.auditLogs
+}
+
+func (m *Models) Grants() interfaces.GrantModels {
+	return m.grants
+}
+
+func (m *Models) KYC() interfaces.KYCModels {
+	return m.kyc
+}
+
+func (m *Models) Outbox() interfaces.OutboxModels {
+	return m.outbox
+}
+
+func (m *Models) Suppressions() interfaces.SuppressionModels {
+	return m.suppressions
+}
+
+// NewModel builds the models. With rls set, user queries run in transactions
+// that set the settings the Postgres row-level security policies check.
+// keyring encrypts identity numbers on user profiles and KYC documents.
+func NewModel(DB *gorm.DB, rls bool, keyring *fieldcrypt.Keyring) interfaces.Models {
+	// Shared so that permission changes also invalidate role lookups
+	cache := newRoleCache(time.Minute)
+
 	return &Models{
-		users:       &UserModels{DB: DB},
-		roles:       &UserRoles{DB: DB},
-		permissions: &UserPermissions{DB: DB},
-		verCodes:    &VerificationCodes{DB: DB},
+		users:        &UserModels{DB: DB, RLS: rls, Keyring: keyring},
+		roles:        &UserRoles{DB: DB, cache: cache},
+		permissions:  &UserPermissions{DB: DB, cache: cache},
+		verCodes:     &VerificationCodes{DB: DB},
+		auditLogs:    &AuditLogs{DB: DB},
+		grants:       &PermissionGrants{DB: DB},
+		kyc:          &KYCVerifications{DB: DB, Keyring: keyring},
+		outbox:       &Outbox{DB: DB},
+		suppressions: &Suppressions{DB: DB},
 	}
 }
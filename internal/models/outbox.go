@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Outbox struct {
+	DB *gorm.DB
+}
+
+func newOutboxEmail(email *interfaces.EmailMsg, now time.Time) *interfaces.OutboxEmail {
+	return &interfaces.OutboxEmail{
+		To:            email.To,
+		From:          email.From,
+		Subject:       email.Subject,
+		BodyText:      email.BodyText,
+		BodyHTML:      email.BodyHTML,
+		Status:        interfaces.EmailPending,
+		NextAttemptAt: now,
+	}
+}
+
+// CreateEmail queues an email that isn't tied to another write.
+func (o *Outbox) CreateEmail(email *interfaces.EmailMsg) error {
+	if err := o.DB.Create(newOutboxEmail(email, time.Now())).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimDueEmails returns up to limit pending emails due by now and pushes
+// their next attempt back by lease, so other workers skip them while they are
+// sent. If the worker dies mid-send, the emails are picked up again once the
+// lease runs out.
+func (o *Outbox) ClaimDueEmails(now time.Time, lease time.Duration, limit int) ([]interfaces.OutboxEmail, error) {
+	var emails []interfaces.OutboxEmail
+	err := o.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", interfaces.EmailPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&emails).Error; err != nil {
+
+			return err
+		}
+		if len(emails) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(emails))
+		for i, email := range emails {
+			ids[i] = email.ID
+		}
+		return tx.Model(&interfaces.OutboxEmail{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (o *Outbox) MarkEmailSent(emailID uuid.UUID, at time.Time) error {
+	if err := o.DB.Model(&interfaces.OutboxEmail{}).Where("id = ?", emailID).Updates(map[string]any{
+		"status":     interfaces.EmailSent,
+		"sent_at":    at,
+		"last_error": "",
+		"body_text":  "",
+		"body_html":  "",
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarkEmailFailed records a failed attempt: the attempt count, status, next
+// attempt and error on the email.
+func (o *Outbox) MarkEmailFailed(email *interfaces.OutboxEmail) error {
+	if err := o.DB.Model(&interfaces.OutboxEmail{}).
+		Where("id = ?", email.ID).
+		Select("attempts", "status", "next_attempt_at", "last_error").
+		Updates(&email).Error; err != nil {
+
+		return err
+	}
+	return nil
+}
+
+func (o *Outbox) GetEmailByID(emailID uuid.UUID) (*interfaces.OutboxEmail, error) {
+	var email interfaces.OutboxEmail
+	if err := o.DB.Where("id = ?", emailID).First(&email).Error; err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+// GetEmailsByStatus returns emails with the status, most recent first.
+func (o *Outbox) GetEmailsByStatus(status string) ([]interfaces.OutboxEmail, error) {
+	var emails []interfaces.OutboxEmail
+	if err := o.DB.Where("status = ?", status).Order("created_at DESC").Limit(500).Find(&emails).Error; err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// RetryEmail queues an unsent email for immediate delivery with a fresh set
+// of attempts. It returns gorm.ErrRecordNotFound for sent or missing emails.
+func (o *Outbox) RetryEmail(emailID uuid.UUID, at time.Time) error {
+	result := o.DB.Model(&interfaces.OutboxEmail{}).
+		Where("id = ? AND status <> ?", emailID, interfaces.EmailSent).
+		Updates(map[string]any{
+			"status":          interfaces.EmailPending,
+			"attempts":        0,
+			"next_attempt_at": at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
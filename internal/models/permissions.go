@@ -6,12 +6,13 @@ import (
 )
 
 type UserPermissions struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	cache *roleCache
 }
 
 func (p *UserPermissions) GetAllPermissions() ([]interfaces.Permission, error) {
 	var perms []interfaces.Permission
-	if err := p.DB.Find(&perms).Error; err != nil {
+	if err := p.DB.Order("id").Find(&perms).Error; err != nil {
 		return nil, err
 	}
 	return perms, nil
@@ -19,12 +20,20 @@ func (p *UserPermissions) GetAllPermissions() ([]interfaces.Permission, error) {
 
 func (p *UserPermissions) GetPermission(perm *interfaces.Permission) (*interfaces.Permission, error) {
 	var permission interfaces.Permission
-	if err := p.DB.First(&permission, perm).Error; err != nil {
+	if err := p.DB.Where("id = ?", perm.ID).First(&permission).Error; err != nil {
 		return nil, err
 	}
 	return &permission, nil
 }
 
+func (p *UserPermissions) GetPermissionsByIDs(permIDs []string) ([]interfaces.Permission, error) {
+	var perms []interfaces.Permission
+	if err := p.DB.Where("id IN ?", permIDs).Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
 func (p *UserPermissions) CreatePermission(perm *interfaces.Permission) error {
 	if err := p.DB.Create(&perm).Error; err != nil {
 		return err
@@ -33,14 +42,22 @@ func (p *UserPermissions) CreatePermission(perm *interfaces.Permission) error {
 }
 
 func (p *UserPermissions) UpdatePermission(perm *interfaces.Permission) error {
-	if err := p.DB.Model(&interfaces.Permission{}).Updates(&perm).Error; err != nil {
+	defer p.cache.reset()
+
+	if err := p.DB.Model(&interfaces.Permission{}).Where("id = ?", perm.ID).Updates(map[string]interface{}{
+		"description": perm.Description,
+		"min_tier":    perm.MinTier,
+	}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (p *UserPermissions) DeletePermission(perm interfaces.Permission) error {
-	if err := p.DB.Delete(&interfaces.Permission{}, perm).Error; err != nil {
+	defer p.cache.reset()
+
+	// Hard delete so the permission ID can be reused, along with its role_permissions rows
+	if err := p.DB.Unscoped().Select("Roles").Delete(&interfaces.Permission{ID: perm.ID}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// profileField is a profile field whose changes are recorded in its history.
+type profileField struct {
+	// name is the struct field's name, as PatchUserProfile takes it
+	name string
+	// key names the field in the history, as clients send it
+	key string
+	// redacted fields are encrypted at rest, so their values aren't recorded
+	redacted bool
+	value    func(p *interfaces.UserProfile) string
+}
+
+var profileFields = []profileField{
+	{name: "FirstName", key: "firstName", value: func(p *interfaces.UserProfile) string { return p.FirstName }},
+	{name: "LastName", key: "lastName", value: func(p *interfaces.UserProfile) string { return p.LastName }},
+	{name: "Bio", key: "bio", value: func(p *interfaces.UserProfile) string { return p.Bio }},
+	{name: "DateOfBirth", key: "dateOfBirth", value: func(p *interfaces.UserProfile) string {
+		if p.DateOfBirth == nil {
+			return ""
+		}
+		return p.DateOfBirth.Format(time.DateOnly)
+	}},
+	{name: "ProfilePictureUrl", key: "profilePictureUrl", value: func(p *interfaces.UserProfile) string { return p.ProfilePictureUrl }},
+	{name: "PictureIsPublic", key: "pictureIsPublic", value: func(p *interfaces.UserProfile) string { return strconv.FormatBool(p.PictureIsPublic) }},
+	{name: "NIN", key: "nin", redacted: true, value: func(p *interfaces.UserProfile) string { return p.NIN }},
+	{name: "BVN", key: "bvn", redacted: true, value: func(p *interfaces.UserProfile) string { return p.BVN }},
+}
+
+// profileFieldNames are the names of all the recorded fields.
+func profileFieldNames() []string {
+	names := make([]string, len(profileFields))
+	for i, f := range profileFields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// setProfileFields are the names of the recorded fields that GORM's
+// Updates will write from profile, which skips zero values.
+func setProfileFields(profile *interfaces.UserProfile) []string {
+	v := reflect.ValueOf(profile).Elem()
+	var names []string
+	for _, f := range profileFields {
+		if !v.FieldByName(f.name).IsZero() {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// diffProfile lists the named fields whose values differ between old and
+// new profiles.
+func diffProfile(old, new *interfaces.UserProfile, names []string) []interfaces.ProfileFieldChange {
+	changed := map[string]bool{}
+	for _, name := range names {
+		changed[name] = true
+	}
+
+	var fields []interfaces.ProfileFieldChange
+	for _, f := range profileFields {
+		if !changed[f.name] {
+			continue
+		}
+		oldValue, newValue := f.value(old), f.value(new)
+		if oldValue == newValue {
+			continue
+		}
+		if f.redacted {
+			fields = append(fields, interfaces.ProfileFieldChange{Field: f.key, Redacted: true})
+		} else {
+			fields = append(fields, interfaces.ProfileFieldChange{Field: f.key, Old: oldValue, New: newValue})
+		}
+	}
+	return fields
+}
+
+// lockProfile loads and decrypts a profile, locking its row until tx ends
+// so its history records changes in the order they were made.
+func (u *UserModels) lockProfile(tx *gorm.DB, userID uuid.UUID) (*interfaces.UserProfile, error) {
+	var profile interfaces.UserProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	if err := u.openProfile(&profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// recordProfileChange adds a change to a profile's history, unless nothing
+// changed. An actorID of uuid.Nil records a change made by the system.
+func recordProfileChange(tx *gorm.DB, userID uuid.UUID, version int, action string, actorID uuid.UUID, fields []interfaces.ProfileFieldChange) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	change := interfaces.ProfileChange{
+		UserID:  userID,
+		Version: version,
+		Action:  action,
+		Fields:  fields,
+	}
+	if actorID != uuid.Nil {
+		change.ActorID = &actorID
+	}
+	return tx.Create(&change).Error
+}
+
+// GetProfileHistory returns up to limit changes to a user's profile, newest
+// first, from before beforeVersion if it is set.
+func (u *UserModels) GetProfileHistory(userID uuid.UUID, beforeVersion int, limit int) ([]interfaces.ProfileChange, error) {
+	var changes []interfaces.ProfileChange
+	if err := u.scoped(func(tx *gorm.DB) error {
+		query := tx.Where("user_id = ?", userID)
+		if beforeVersion > 0 {
+			query = query.Where("version < ?", beforeVersion)
+		}
+		return query.Order("version DESC").Order("created_at DESC").Limit(limit).Find(&changes).Error
+	}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
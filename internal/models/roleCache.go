@@ -0,0 +1,53 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+)
+
+// roleCache holds each role's effective permissions and conditions, including
+// those inherited from parent roles. Writes to roles or permissions reset it; the
+// TTL bounds staleness when several instances share a database.
+type roleCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]roleCacheEntry
+}
+
+type roleCacheEntry struct {
+	permissions []string
+	conditions  []policy.Condition
+	expiresAt   time.Time
+}
+
+func newRoleCache(ttl time.Duration) *roleCache {
+	return &roleCache{ttl: ttl, entries: map[string]roleCacheEntry{}}
+}
+
+func (rc *roleCache) get(roleID string) (roleCacheEntry, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	entry, ok := rc.entries[roleID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return roleCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (rc *roleCache) set(roleID string, entry roleCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry.expiresAt = time.Now().Add(rc.ttl)
+	rc.entries[roleID] = entry
+}
+
+func (rc *roleCache) reset() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries = map[string]roleCacheEntry{}
+}
@@ -1,17 +1,33 @@
 package models
 
 import (
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/db"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"github.com/InternPulse/famtrust-backend-auth/internal/policy"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserRoles struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	cache *roleCache
 }
 
 func (r *UserRoles) GetAllRoles() ([]interfaces.Role, error) {
 	var roles []interfaces.Role
-	if err := r.DB.Find(&roles).Error; err != nil {
+	if err := r.DB.Preload("Permissions").Preload("Parents").Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetGlobalRoles returns the roles that don't belong to a family group.
+func (r *UserRoles) GetGlobalRoles() ([]interfaces.Role, error) {
+	var roles []interfaces.Role
+	if err := r.DB.Preload("Permissions").Preload("Parents").Where("group_id IS NULL").Order("id").Find(&roles).Error; err != nil {
 		return nil, err
 	}
 	return roles, nil
@@ -19,13 +35,31 @@ func (r *UserRoles) GetAllRoles() ([]interfaces.Role, error) {
 
 func (r *UserRoles) GetRoleByID(roleID string) (*interfaces.Role, error) {
 	var role interfaces.Role
-	if err := r.DB.First(&role, roleID).Error; err != nil {
+	if err := r.DB.Preload("Permissions").Preload("Parents").Where("id = ?", roleID).First(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
+func (r *UserRoles) GetRolesByGroup(groupID uuid.UUID) ([]interfaces.Role, error) {
+	var roles []interfaces.Role
+	if err := r.DB.Preload("Permissions").Preload("Parents").Where("group_id = ?", groupID).Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *UserRoles) GetChildRoleIDs(roleID string) ([]string, error) {
+	var ids []string
+	if err := r.DB.Table("role_parents").Where("parent_id = ?", roleID).Pluck("role_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *UserRoles) CreateRole(role *interfaces.Role) error {
+	defer r.cache.reset()
+
 	if err := r.DB.Create(&role).Error; err != nil {
 		return err
 	}
@@ -33,14 +67,183 @@ func (r *UserRoles) CreateRole(role *interfaces.Role) error {
 }
 
 func (r *UserRoles) UpdateRoleByID(role *interfaces.Role) error {
-	if err := r.DB.Model(&interfaces.Role{}).Updates(&role).Error; err != nil {
+	defer r.cache.reset()
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&interfaces.Role{}).Where("id = ?", role.ID).Update("description", role.Description).Error; err != nil {
+			return err
+		}
+
+		// A nil condition list leaves the role's conditions untouched
+		if role.Conditions != nil {
+			if err := tx.Model(&interfaces.Role{ID: role.ID}).Select("conditions").Updates(&interfaces.Role{Conditions: role.Conditions}).Error; err != nil {
+				return err
+			}
+		}
+
+		// A nil permission list leaves the role's permissions untouched
+		if role.Permissions == nil {
+			return nil
+		}
+
+		return tx.Model(&interfaces.Role{ID: role.ID}).Association("Permissions").Replace(role.Permissions)
+	})
+}
+
+// SetRoleParents replaces the roles a role inherits permissions from,
+// refusing changes that would make a role its own ancestor.
+func (r *UserRoles) SetRoleParents(roleID string, parentIDs []string) error {
+	defer r.cache.reset()
+
+	for _, parentID := range parentIDs {
+		ancestors, err := r.ancestors(parentID)
+		if err != nil {
+			return err
+		}
+		if parentID == roleID || slices.Contains(ancestors, roleID) {
+			return interfaces.ErrRoleCycle
+		}
+	}
+
+	parents := []interfaces.Role{}
+	for _, parentID := range parentIDs {
+		parents = append(parents, interfaces.Role{ID: parentID})
+	}
+
+	return r.DB.Model(&interfaces.Role{ID: roleID}).Association("Parents").Replace(parents)
+}
+
+func (r *UserRoles) DeleteRoleByID(roleID string) error {
+	defer r.cache.reset()
+
+	// Hard delete so the role ID can be reused, along with its join table rows
+	if err := r.DB.Unscoped().Select("Permissions", "Parents").Delete(&interfaces.Role{ID: roleID}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *UserRoles) DeleteRoleByID(roleID string) error {
-	if err := r.DB.Delete(&interfaces.Role{}, roleID).Error; err != nil {
+func (r *UserRoles) AddPermissionsToRole(roleID string, perms []interfaces.Permission) error {
+	defer r.cache.reset()
+
+	return r.DB.Model(&interfaces.Role{ID: roleID}).Association("Permissions").Append(perms)
+}
+
+func (r *UserRoles) RemovePermissionsFromRole(roleID string, perms []interfaces.Permission) error {
+	defer r.cache.reset()
+
+	return r.DB.Model(&interfaces.Role{ID: roleID}).Association("Permissions").Delete(perms)
+}
+
+// GetEffectivePermissions returns the role's own permissions together with
+// every permission inherited from its ancestors.
+func (r *UserRoles) GetEffectivePermissions(roleID string) ([]string, error) {
+	entry, err := r.resolve(roleID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.permissions, nil
+}
+
+// GetEffectiveConditions returns the role's own conditions together with
+// every condition inherited from its ancestors, all of which apply.
+func (r *UserRoles) GetEffectiveConditions(roleID string) ([]policy.Condition, error) {
+	entry, err := r.resolve(roleID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.conditions, nil
+}
+
+// resolve walks the role and its ancestors, caching what it finds.
+func (r *UserRoles) resolve(roleID string) (roleCacheEntry, error) {
+	if entry, ok := r.cache.get(roleID); ok {
+		return entry, nil
+	}
+
+	entry := roleCacheEntry{permissions: []string{}}
+	visited := map[string]bool{}
+	queue := []string{roleID}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		role, err := r.GetRoleByID(id)
+		if err != nil {
+			return roleCacheEntry{}, err
+		}
+
+		for _, perm := range role.Permissions {
+			if !slices.Contains(entry.permissions, perm.ID) {
+				entry.permissions = append(entry.permissions, perm.ID)
+			}
+		}
+		entry.conditions = append(entry.conditions, role.Conditions...)
+		for _, parent := range role.Parents {
+			queue = append(queue, parent.ID)
+		}
+	}
+
+	r.cache.set(roleID, entry)
+	return entry, nil
+}
+
+// ancestors returns the IDs of every role the given role inherits from.
+func (r *UserRoles) ancestors(roleID string) ([]string, error) {
+	var found []string
+	queue := []string{roleID}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		var parentIDs []string
+		if err := r.DB.Table("role_parents").Where("role_id = ?", id).Pluck("parent_id", &parentIDs).Error; err != nil {
+			return nil, err
+		}
+
+		for _, parentID := range parentIDs {
+			if !slices.Contains(found, parentID) {
+				found = append(found, parentID)
+				queue = append(queue, parentID)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// CountUsersWithRole counts holders of the role in every family group, so a
+// role still in use anywhere isn't deleted.
+func (r *UserRoles) CountUsersWithRole(roleID string) (int64, error) {
+	var count int64
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := db.BypassRLS(tx); err != nil {
+			return err
+		}
+		return tx.Model(&interfaces.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *UserRoles) GetManifestVersion() (int, error) {
+	var version int
+	if err := r.DB.Model(&interfaces.RBACManifest{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (r *UserRoles) SetManifestVersion(version int) error {
+	manifest := interfaces.RBACManifest{Version: version, AppliedAt: time.Now()}
+	if err := r.DB.Save(&manifest).Error; err != nil {
 		return err
 	}
 	return nil
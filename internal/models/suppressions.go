@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Suppressions struct {
+	DB *gorm.DB
+}
+
+// normaliseAddress makes lookups case-insensitive, as mailbox providers are
+func normaliseAddress(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// SuppressAddress adds the address to the suppression list, or updates the
+// reason and detail if it is already there.
+func (s *Suppressions) SuppressAddress(suppression *interfaces.SuppressedAddress) error {
+	suppression.Email = normaliseAddress(suppression.Email)
+	if err := s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "updated_at"}),
+	}).Create(&suppression).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Suppressions) GetSuppression(email string) (*interfaces.SuppressedAddress, error) {
+	var suppression interfaces.SuppressedAddress
+	if err := s.DB.Where("email = ?", normaliseAddress(email)).First(&suppression).Error; err != nil {
+		return nil, err
+	}
+	return &suppression, nil
+}
+
+// GetSuppressions returns every suppressed address, most recent first.
+func (s *Suppressions) GetSuppressions() ([]interfaces.SuppressedAddress, error) {
+	var suppressions []interfaces.SuppressedAddress
+	if err := s.DB.Order("updated_at DESC").Find(&suppressions).Error; err != nil {
+		return nil, err
+	}
+	return suppressions, nil
+}
+
+// DeleteSuppression takes the address off the list, so mail to it is sent
+// again. It returns gorm.ErrRecordNotFound if the address isn't listed.
+func (s *Suppressions) DeleteSuppression(email string) error {
+	// Unscoped so a later bounce can insert the address again
+	result := s.DB.Unscoped().Where("email = ?", normaliseAddress(email)).Delete(&interfaces.SuppressedAddress{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *Suppressions) IsSuppressed(email string) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&interfaces.SuppressedAddress{}).Where("email = ?", normaliseAddress(email)).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -2,19 +2,130 @@ package models
 
 import (
 	"errors"
+	"time"
 
+	"github.com/InternPulse/famtrust-backend-auth/internal/db"
+	"github.com/InternPulse/famtrust-backend-auth/internal/fieldcrypt"
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserModels struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	RLS     bool
+	Keyring *fieldcrypt.Keyring
+
+	// scope and bypass are set by In and Unscoped; with neither, scoped
+	// queries see no rows under row-level security
+	scope  *interfaces.Scope
+	bypass bool
+}
+
+func (u *UserModels) In(scope interfaces.Scope) interfaces.UserModels {
+	view := *u
+	view.scope, view.bypass = &scope, false
+	return &view
+}
+
+func (u *UserModels) Unscoped() interfaces.UserModels {
+	view := *u
+	view.scope, view.bypass = nil, true
+	return &view
+}
+
+// sealProfile encrypts any plaintext NIN or BVN set on the profile.
+func (u *UserModels) sealProfile(profile *interfaces.UserProfile) error {
+	if profile.NIN != "" {
+		sealed, err := u.Keyring.Seal("nin", profile.NIN)
+		if err != nil {
+			return err
+		}
+		profile.NINCiphertext, profile.NINKeyID, profile.NINIndex = sealed.Ciphertext, sealed.KeyID, &sealed.Index
+	}
+	if profile.BVN != "" {
+		sealed, err := u.Keyring.Seal("bvn", profile.BVN)
+		if err != nil {
+			return err
+		}
+		profile.BVNCiphertext, profile.BVNKeyID, profile.BVNIndex = sealed.Ciphertext, sealed.KeyID, &sealed.Index
+	}
+	return nil
+}
+
+// openProfile decrypts the profile's NIN and BVN into their plaintext fields.
+func (u *UserModels) openProfile(profile *interfaces.UserProfile) error {
+	var err error
+	if profile.NINCiphertext != "" {
+		if profile.NIN, err = u.Keyring.Open(profile.NINCiphertext, profile.NINKeyID); err != nil {
+			return err
+		}
+	}
+	if profile.BVNCiphertext != "" {
+		if profile.BVN, err = u.Keyring.Open(profile.BVNCiphertext, profile.BVNKeyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inGroup runs fn with row-level security scoped to one family group.
+func (u *UserModels) inGroup(groupID uuid.UUID, fn func(tx *gorm.DB) error) error {
+	if !u.RLS {
+		return fn(u.DB)
+	}
+
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := db.ScopeToGroup(tx, groupID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// scoped runs fn with row-level security scoped as the view was by In or
+// Unscoped.
+func (u *UserModels) scoped(fn func(tx *gorm.DB) error) error {
+	if !u.RLS {
+		return fn(u.DB)
+	}
+
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		switch {
+		case u.bypass:
+			if err := db.BypassRLS(tx); err != nil {
+				return err
+			}
+		case u.scope != nil:
+			if err := db.ScopeToUser(tx, u.scope.UserID, u.scope.GroupID); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// unscoped runs fn across all family groups, for lookups by email, ID
+// number or picture URL that must see every user whatever the view.
+func (u *UserModels) unscoped(fn func(tx *gorm.DB) error) error {
+	if !u.RLS {
+		return fn(u.DB)
+	}
+
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := db.BypassRLS(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
 }
 
 func (u *UserModels) CreateUser(user *interfaces.User) error {
-	if err := u.DB.Create(&user).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Create(&user).Error
+	}); err != nil {
 		return err
 	}
 	return nil
@@ -22,7 +133,9 @@ func (u *UserModels) CreateUser(user *interfaces.User) error {
 
 func (u *UserModels) GetUserByID(userID uuid.UUID) (*interfaces.User, error) {
 	var user interfaces.User
-	if err := u.DB.Preload("Role").Preload("Role.Permissions").Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Preload("Role").Preload("Role.Permissions").Where("id = ?", userID).First(&user).Error
+	}); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -30,21 +143,27 @@ func (u *UserModels) GetUserByID(userID uuid.UUID) (*interfaces.User, error) {
 
 func (u *UserModels) GetUserByEmail(email string) (*interfaces.User, error) {
 	var user interfaces.User
-	if err := u.DB.Where("email = ?", email).Preload("Role").Preload("Role.Permissions").First(&user).Error; err != nil {
+	if err := u.unscoped(func(tx *gorm.DB) error {
+		return tx.Where("email = ?", email).Preload("Role").Preload("Role.Permissions").First(&user).Error
+	}); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 func (u *UserModels) UpdateUser(user *interfaces.User) error {
-	if err := u.DB.Model(&interfaces.User{}).Where("id = ?", user.ID).Updates(&user).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", user.ID).Updates(&user).Error
+	}); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u *UserModels) DeleteUserByID(userID uuid.UUID) error {
-	if err := u.DB.Delete(&interfaces.User{}, userID).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Delete(&interfaces.User{}, userID).Error
+	}); err != nil {
 		return err
 	}
 	return nil
@@ -65,15 +184,94 @@ func (u *UserModels) PasswordMatches(passwordHash string, plainText string) (boo
 	return true, nil
 }
 
-func (u *UserModels) CreateUserProfile(profile *interfaces.UserProfile) error {
-	if err := u.DB.Create(&profile).Error; err != nil {
+// CreateUserProfile creates a profile and starts its history.
+func (u *UserModels) CreateUserProfile(profile *interfaces.UserProfile, actorID uuid.UUID) error {
+	if err := u.sealProfile(profile); err != nil {
+		return err
+	}
+
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&profile).Error; err != nil {
+				return err
+			}
+			fields := diffProfile(&interfaces.UserProfile{}, profile, profileFieldNames())
+			return recordProfileChange(tx, profile.UserID, 1, interfaces.ProfileCreated, actorID, fields)
+		})
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateUserProfile writes the fields of profile that aren't empty, and
+// records the change in its history.
+func (u *UserModels) UpdateUserProfile(profile *interfaces.UserProfile, actorID uuid.UUID) error {
+	if err := u.sealProfile(profile); err != nil {
+		return err
+	}
+
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			old, err := u.lockProfile(tx, profile.UserID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&interfaces.UserProfile{}).Where("user_id = ?", profile.UserID).Updates(&profile).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&interfaces.UserProfile{}).Where("user_id = ?", profile.UserID).UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+				return err
+			}
+			fields := diffProfile(old, profile, setProfileFields(profile))
+			return recordProfileChange(tx, profile.UserID, old.Version+1, interfaces.ProfileUpdated, actorID, fields)
+		})
+	}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *UserModels) UpdateUserProfile(profile *interfaces.UserProfile) error {
-	if err := u.DB.Model(&interfaces.UserProfile{}).Where("user_id = ?", profile.UserID).Updates(&profile).Error; err != nil {
+// identityColumns are the columns that store each encrypted profile field.
+var identityColumns = map[string][]string{
+	"NIN": {"NINCiphertext", "NINKeyID", "NINIndex"},
+	"BVN": {"BVNCiphertext", "BVNKeyID", "BVNIndex"},
+}
+
+// PatchUserProfile writes the named fields of profile, including empty
+// values, if the stored profile is still at version, and records the change
+// in its history. It returns interfaces.ErrStaleProfile if it isn't, and
+// leaves profile.Version at the new version if it is.
+func (u *UserModels) PatchUserProfile(profile *interfaces.UserProfile, fields []string, version int, actorID uuid.UUID) error {
+	if err := u.sealProfile(profile); err != nil {
+		return err
+	}
+
+	columns := []string{"Version", "UpdatedAt"}
+	for _, field := range fields {
+		if sealed, ok := identityColumns[field]; ok {
+			columns = append(columns, sealed...)
+		} else {
+			columns = append(columns, field)
+		}
+	}
+	profile.Version = version + 1
+
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			old, err := u.lockProfile(tx, profile.UserID)
+			if err != nil {
+				return err
+			}
+			if old.Version != version {
+				return interfaces.ErrStaleProfile
+			}
+			if err := tx.Model(&interfaces.UserProfile{}).Where("user_id = ?", profile.UserID).Select(columns).Updates(profile).Error; err != nil {
+				return err
+			}
+			return recordProfileChange(tx, profile.UserID, profile.Version, interfaces.ProfileUpdated, actorID, diffProfile(old, profile, fields))
+		})
+	}); err != nil {
 		return err
 	}
 	return nil
@@ -81,23 +279,86 @@ func (u *UserModels) UpdateUserProfile(profile *interfaces.UserProfile) error {
 
 func (u *UserModels) GetUserProfileByID(userID uuid.UUID) (*interfaces.UserProfile, error) {
 	var profile interfaces.UserProfile
-	if err := u.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Where("user_id = ?", userID).First(&profile).Error
+	}); err != nil {
+		return nil, err
+	}
+	if err := u.openProfile(&profile); err != nil {
 		return nil, err
 	}
 	return &profile, nil
 }
 
-func (u *UserModels) GetUserByNIN(nin int) (*interfaces.User, error) {
+// GetProfilePictureUrls returns the picture URL of every profile whose user
+// hasn't been deleted.
+func (u *UserModels) GetProfilePictureUrls() ([]string, error) {
+	var urls []string
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.UserProfile{}).
+			Joins("JOIN users ON users.id = user_profiles.user_id AND users.deleted_at IS NULL").
+			Pluck("user_profiles.profile_picture_url", &urls).Error
+	}); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+func (u *UserModels) SetPictureIsPublic(userID uuid.UUID, value bool, actorID uuid.UUID) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			old, err := u.lockProfile(tx, userID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&interfaces.UserProfile{}).Where("user_id = ?", userID).Updates(map[string]any{
+				"picture_is_public": value,
+				"version":           gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			fields := diffProfile(old, &interfaces.UserProfile{PictureIsPublic: value}, []string{"PictureIsPublic"})
+			return recordProfileChange(tx, userID, old.Version+1, interfaces.ProfileUpdated, actorID, fields)
+		})
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsPicturePublic reports whether a profile has made the picture at url
+// public.
+func (u *UserModels) IsPicturePublic(url string) (bool, error) {
+	var count int64
+	if err := u.unscoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.UserProfile{}).
+			Where("profile_picture_url = ? AND picture_is_public", url).
+			Count(&count).Error
+	}); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (u *UserModels) GetUserByNIN(nin string) (*interfaces.User, error) {
 	var user interfaces.User
-	if err := u.DB.Where("nin = ?", nin).First(&user).Error; err != nil {
+	if err := u.unscoped(func(tx *gorm.DB) error {
+		return tx.Joins("JOIN user_profiles ON user_profiles.user_id = users.id").
+			Where("user_profiles.nin_index = ?", u.Keyring.BlindIndex("nin", nin)).
+			First(&user).Error
+	}); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (u *UserModels) GetUserByBVN(bvn int) (*interfaces.User, error) {
+func (u *UserModels) GetUserByBVN(bvn string) (*interfaces.User, error) {
 	var user interfaces.User
-	if err := u.DB.Where("nin = ?", bvn).First(&user).Error; err != nil {
+	if err := u.unscoped(func(tx *gorm.DB) error {
+		return tx.Joins("JOIN user_profiles ON user_profiles.user_id = users.id").
+			Where("user_profiles.bvn_index = ?", u.Keyring.BlindIndex("bvn", bvn)).
+			First(&user).Error
+	}); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -105,37 +366,145 @@ func (u *UserModels) GetUserByBVN(bvn int) (*interfaces.User, error) {
 
 func (u *UserModels) SetIsVerified(userID uuid.UUID, value bool) error {
 	// Update the `Active` field of a user with a specific ID
-	if err := u.DB.Model(&interfaces.User{}).Where("id = ?", userID).Update("is_verified", value).Error; err != nil {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Update("is_verified", value).Error
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *UserModels) SetRoleID(userID uuid.UUID, roleID string) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Update("role_id", roleID).Error
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *UserModels) SetHas2FA(userID uuid.UUID, value bool) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Update("has_2fa", value).Error
+	}); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (u *UserModels) SetMutedAlerts(userID uuid.UUID, alerts []string) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Select("muted_alerts").Updates(&interfaces.User{MutedAlerts: alerts}).Error
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeSessions invalidates every token issued to the user before at. Token
+// issue times are whole seconds, so at is stored truncated to the second.
+func (u *UserModels) RevokeSessions(userID uuid.UUID, at time.Time) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Update("sessions_revoked_at", at.Truncate(time.Second)).Error
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LockAccount freezes the account and revokes its sessions, for when the
+// owner reports activity that wasn't theirs.
+func (u *UserModels) LockAccount(userID uuid.UUID, at time.Time) error {
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Model(&interfaces.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"is_frozen":           true,
+			"sessions_revoked_at": at.Truncate(time.Second),
+		}).Error
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSessionUser is a lighter GetUserByID for checking every request's
+// token, loading only the user's group and when their sessions were revoked.
+func (u *UserModels) GetSessionUser(userID uuid.UUID) (*interfaces.User, error) {
+	var user interfaces.User
+	if err := u.scoped(func(tx *gorm.DB) error {
+		return tx.Select("id", "default_group", "sessions_revoked_at").Where("id = ?", userID).First(&user).Error
+	}); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RecordDevice notes a login from the device, reporting whether it is new.
+// A user's first device isn't reported as new, as there is nothing to
+// compare it against.
+func (u *UserModels) RecordDevice(device *interfaces.KnownDevice) (bool, error) {
+	var isNew bool
+	err := u.DB.Transaction(func(tx *gorm.DB) error {
+		var known []interfaces.KnownDevice
+		if err := tx.Where("user_id = ?", device.UserID).Find(&known).Error; err != nil {
+			return err
+		}
+
+		for _, k := range known {
+			if k.Fingerprint == device.Fingerprint {
+				return tx.Model(&k).Updates(map[string]any{
+					"user_agent":   device.UserAgent,
+					"ip_address":   device.IPAddress,
+					"last_seen_at": device.LastSeenAt,
+				}).Error
+			}
+		}
+
+		isNew = len(known) > 0
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&device).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return isNew, nil
+}
+
 func (u *UserModels) GetUsersByDefaultGroup(groupID uuid.UUID) (*[]interfaces.User, error) {
 	var users []interfaces.User
-	if err := u.DB.Preload("Role").
-		Preload("Role.Permissions").
-		Preload("UserProfile").
-		Where("default_group = ?", groupID).
-		Omit("password_hash").
-		Find(&users).Error; err != nil {
+	if err := u.inGroup(groupID, func(tx *gorm.DB) error {
+		return tx.Preload("Role").
+			Preload("Role.Permissions").
+			Preload("UserProfile").
+			Where("default_group = ?", groupID).
+			Omit("password_hash").
+			Find(&users).Error
+	}); err != nil {
 
 		return nil, err
 	}
+	for i := range users {
+		if err := u.openProfile(&users[i].UserProfile); err != nil {
+			return nil, err
+		}
+	}
 	return &users, nil
 }
 
 func (u *UserModels) GetUserByDefaultGroup(userID uuid.UUID, groupID uuid.UUID) (*interfaces.User, error) {
 	var user interfaces.User
-	if err := u.DB.Preload("Role").
-		Preload("Role.Permissions").
-		Preload("UserProfile").
-		Where("id = ?", userID).
-		Where("default_group = ?", groupID).
-		Omit("password_hash").
-		First(&user).Error; err != nil {
+	if err := u.inGroup(groupID, func(tx *gorm.DB) error {
+		return tx.Preload("Role").
+			Preload("Role.Permissions").
+			Preload("UserProfile").
+			Where("id = ?", userID).
+			Where("default_group = ?", groupID).
+			Omit("password_hash").
+			First(&user).Error
+	}); err != nil {
 
 		return nil, err
 	}
+	if err := u.openProfile(&user.UserProfile); err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -55,6 +57,43 @@ func (v *VerificationCodes) Delete2FACodeByUserID(UserID uuid.UUID) error {
 	return nil
 }
 
+// lockoutCodeLifetime is how long an alert's "this wasn't me" link works
+const lockoutCodeLifetime = 7 * 24 * time.Hour
+
+// GetLockoutCodeByID finds a lockout code sent in the last
+// lockoutCodeLifetime; older ones are treated as not found.
+func (v *VerificationCodes) GetLockoutCodeByID(codeID uuid.UUID) (*interfaces.VerCode, error) {
+	var code interfaces.VerCode
+	if err := v.DB.Where("type = ?", "lockout").Where("id = ?", codeID).
+		Where("created_at > ?", time.Now().Add(-lockoutCodeLifetime)).
+		First(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (v *VerificationCodes) DeleteLockoutCodesByUserID(UserID uuid.UUID) error {
+	if err := v.DB.Where("type = ?", "lockout").Where("user_id = ?", UserID).Delete(&interfaces.VerCode{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateVerificationCodeWithEmail creates the code and queues the email
+// compose builds for it in one transaction, so neither exists without the other.
+func (v *VerificationCodes) CreateVerificationCodeWithEmail(verCode *interfaces.VerCode, compose func(verCode *interfaces.VerCode) (*interfaces.EmailMsg, error)) error {
+	return v.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&verCode).Error; err != nil {
+			return err
+		}
+		email, err := compose(verCode)
+		if err != nil {
+			return err
+		}
+		return tx.Create(newOutboxEmail(email, time.Now())).Error
+	})
+}
+
 func (v *VerificationCodes) CreateVerificationCode(verCode *interfaces.VerCode) error {
 	if err := v.DB.Create(&verCode).Error; err != nil {
 		return err
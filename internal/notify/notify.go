@@ -0,0 +1,67 @@
+// Package notify emails users security alerts about account events, each
+// with a "this wasn't me" link that locks the account.
+package notify
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/emails"
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Notifier turns account events into alert emails.
+type Notifier struct {
+	models interfaces.Models
+	// baseURL is where this service is publicly reachable, for the lockout link
+	baseURL string
+	// location is the time zone alert times are shown in
+	location *time.Location
+}
+
+// NewNotifier queues alerts in the outbox, so events never wait on the mail
+// server. Lockout links point at baseURL.
+func NewNotifier(models interfaces.Models, baseURL string) *Notifier {
+	// FamTrust users are in Nigeria; WAT has no daylight saving
+	return &Notifier{models: models, baseURL: baseURL, location: time.FixedZone("WAT", 60*60)}
+}
+
+// Publish emails the user about the event, unless they've muted it and it
+// isn't critical. Failures are logged: the event has already happened.
+func (n *Notifier) Publish(event *interfaces.AccountEvent) {
+	if err := n.notify(event); err != nil {
+		log.Printf("Failed to send %s alert to user %s: %v", event.Type, event.UserID, err)
+	}
+}
+
+func (n *Notifier) notify(event *interfaces.AccountEvent) error {
+	user, err := n.models.Users().Unscoped().GetUserByID(event.UserID)
+	if err != nil {
+		return err
+	}
+
+	critical := interfaces.IsCriticalEvent(event.Type)
+	if !critical && slices.Contains(user.MutedAlerts, event.Type) {
+		return nil
+	}
+
+	// Each alert gets its own lockout code, valid for a week or until one is
+	// used, queued together with the alert so neither exists without the other
+	lockout := interfaces.VerCode{UserID: user.ID, Type: "lockout"}
+	return n.models.VerCodes().CreateVerificationCodeWithEmail(&lockout, func(lockout *interfaces.VerCode) (*interfaces.EmailMsg, error) {
+		alert, err := emails.Render(event.Type, user.Locale, emails.Data{
+			Link:      n.baseURL + "/api/v1/security/lockout?code=" + lockout.ID.String(),
+			Time:      event.At.In(n.location).Format("2 Jan 2006 15:04 MST"),
+			Device:    event.UserAgent,
+			IPAddress: event.IPAddress,
+			Role:      event.Role,
+			Critical:  critical,
+		})
+		if err != nil {
+			return nil, err
+		}
+		alert.To = user.Email
+		return alert, nil
+	})
+}
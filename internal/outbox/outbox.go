@@ -0,0 +1,121 @@
+// Package outbox delivers the emails queued in the outbox table in the
+// background, retrying failed sends with exponential backoff.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Worker polls the outbox for due emails and sends them. Emails that fail
+// MaxAttempts times are marked dead for an admin to inspect and retry.
+type Worker struct {
+	Models interfaces.Models
+	Mailer interfaces.Mailer
+
+	// Interval is how often the outbox is polled
+	Interval time.Duration
+	// BatchSize caps the emails claimed per poll
+	BatchSize int
+	// Lease is how long claimed emails are hidden from other workers
+	Lease time.Duration
+	// MaxAttempts is how many sends are tried before an email is dead
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure, doubled after each
+	// further one up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// NewWorker returns a worker with the default schedule: up to 8 attempts
+// over roughly an hour.
+func NewWorker(models interfaces.Models, mailer interfaces.Mailer) *Worker {
+	return &Worker{
+		Models:      models,
+		Mailer:      mailer,
+		Interval:    5 * time.Second,
+		BatchSize:   20,
+		Lease:       10 * time.Minute,
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+	}
+}
+
+// Run delivers due emails every Interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.deliver(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver sends one batch of due emails, recording each outcome.
+func (w *Worker) deliver(ctx context.Context) {
+	emails, err := w.Models.Outbox().ClaimDueEmails(time.Now(), w.Lease, w.BatchSize)
+	if err != nil {
+		log.Printf("Failed to claim outbox emails: %v", err)
+		return
+	}
+
+	for i := range emails {
+		if ctx.Err() != nil {
+			// Unsent claims are retried once their lease runs out
+			return
+		}
+		w.send(&emails[i])
+	}
+}
+
+func (w *Worker) send(email *interfaces.OutboxEmail) {
+	err := w.Mailer.SendMail(&interfaces.EmailMsg{
+		Subject:  email.Subject,
+		From:     email.From,
+		To:       email.To,
+		BodyText: email.BodyText,
+		BodyHTML: email.BodyHTML,
+	})
+	if err == nil {
+		if err := w.Models.Outbox().MarkEmailSent(email.ID, time.Now()); err != nil {
+			log.Printf("Failed to mark outbox email %s sent: %v", email.ID, err)
+		}
+		return
+	}
+
+	email.Attempts++
+	email.LastError = err.Error()
+	email.NextAttemptAt = time.Now().Add(w.Backoff(email.Attempts))
+	if errors.Is(err, interfaces.ErrAddressSuppressed) {
+		// No retry will reach a suppressed address
+		email.Status = interfaces.EmailDead
+	} else if email.Attempts >= w.MaxAttempts {
+		email.Status = interfaces.EmailDead
+		log.Printf("Outbox email %s is dead after %d attempts: %v", email.ID, email.Attempts, err)
+	}
+
+	if err := w.Models.Outbox().MarkEmailFailed(email); err != nil {
+		log.Printf("Failed to record outbox email %s failure: %v", email.ID, err)
+	}
+}
+
+// Backoff is the wait before the next send after the given number of failed
+// attempts.
+func (w *Worker) Backoff(attempts int) time.Duration {
+	delay := w.BaseDelay
+	for i := 1; i < attempts && delay < w.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, w.MaxDelay)
+}
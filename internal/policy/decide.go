@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Source is one way a subject holds permissions: through their role, or
+// through a temporary grant. Conditions only restrict the source carrying them.
+type Source struct {
+	Name        string
+	Permissions []string
+	Conditions  []Condition
+}
+
+// Decision is the outcome of checking one permission against a subject's sources.
+type Decision struct {
+	Allowed bool
+	Reason  string
+	// Source is the name of the source that allowed the permission
+	Source string
+	// Conditions apply to the allowing source; deferred ones must be enforced by the caller
+	Conditions []Condition
+	Deferred   []Condition
+}
+
+// Decide allows permission if any source holding it has no failing
+// conditions, preferring the least restricted such source.
+func Decide(sources []Source, permission string, ctx Context) Decision {
+	var best *Decision
+	var failure string
+
+	for _, source := range sources {
+		if !slices.Contains(source.Permissions, permission) {
+			continue
+		}
+
+		candidate := Decision{Allowed: true, Source: source.Name}
+		failed := ""
+		for _, cond := range source.Conditions {
+			if !cond.AppliesTo(permission) {
+				continue
+			}
+			candidate.Conditions = append(candidate.Conditions, cond)
+
+			result, reason := cond.Evaluate(ctx)
+			switch result {
+			case Fail:
+				if failed == "" {
+					failed = reason
+				}
+			case Deferred:
+				candidate.Deferred = append(candidate.Deferred, cond)
+			}
+		}
+
+		if failed != "" {
+			if failure == "" {
+				failure = fmt.Sprintf("%s granted by %s but %s", permission, source.Name, failed)
+			}
+			continue
+		}
+
+		if best == nil || len(candidate.Conditions) < len(best.Conditions) {
+			best = &candidate
+		}
+	}
+
+	if best != nil {
+		return *best
+	}
+	if failure == "" {
+		failure = fmt.Sprintf("subject does not hold %s", permission)
+	}
+	return Decision{Reason: failure}
+}
@@ -0,0 +1,194 @@
+// Package policy evaluates attribute-based conditions attached to roles and
+// permission grants, such as amount ceilings and time windows.
+package policy
+
+import (
+	"fmt"
+	"time"
+	// Time windows name IANA zones; don't depend on the host having them
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+// Condition types
+const (
+	MaxAmount     = "maxAmount"
+	TimeWindow    = "timeWindow"
+	ResourceOwner = "resourceOwner"
+	VerifiedEmail = "verifiedEmail"
+	Session2FA    = "session2FA"
+)
+
+// Periods a MaxAmount condition can apply over
+const (
+	PerTransaction = "transaction"
+	PerDay         = "day"
+)
+
+// DefaultTimezone is used by time windows that don't name one
+const DefaultTimezone = "Africa/Lagos"
+
+// Condition restricts when a permission may be used. Permission names the
+// permission it applies to; an empty Permission applies to every permission
+// of the role or grant carrying the condition.
+type Condition struct {
+	Type       string `json:"type" example:"maxAmount"`
+	Permission string `json:"permission,omitempty" example:"CanSendtoSubAcc"`
+
+	// MaxAmount: Limit is in kobo, over a single transaction or a calendar day
+	Limit  int64  `json:"limit,omitempty" example:"5000000"`
+	Period string `json:"period,omitempty" example:"day"`
+
+	// TimeWindow: local HH:MM times; From after Until wraps past midnight
+	From     string `json:"from,omitempty" example:"07:00"`
+	Until    string `json:"until,omitempty" example:"21:00"`
+	Timezone string `json:"timezone,omitempty" example:"Africa/Lagos"`
+}
+
+// Context holds the request attributes conditions are evaluated against.
+// Nil fields are unknown; conditions that need them are deferred to the
+// caller rather than failed.
+type Context struct {
+	Now           time.Time
+	SubjectID     uuid.UUID
+	EmailVerified bool
+	Session2FA    *bool
+	Amount        *int64
+	SpentToday    *int64
+	ResourceOwner *uuid.UUID
+}
+
+// Result is the outcome of evaluating one condition.
+type Result int
+
+const (
+	Pass Result = iota
+	Fail
+	Deferred
+)
+
+// Validate checks a condition is well formed.
+func (cond Condition) Validate() error {
+	switch cond.Type {
+	case MaxAmount:
+		if cond.Limit <= 0 {
+			return fmt.Errorf("maxAmount limit must be positive")
+		}
+		if cond.Period != PerTransaction && cond.Period != PerDay {
+			return fmt.Errorf("maxAmount period must be %q or %q", PerTransaction, PerDay)
+		}
+
+	case TimeWindow:
+		// time.Parse takes "7:00" too; the stored times should read alike
+		if _, err := clockMinutes(cond.From); err != nil || len(cond.From) != len("15:04") {
+			return fmt.Errorf("timeWindow from must be HH:MM")
+		}
+		if _, err := clockMinutes(cond.Until); err != nil || len(cond.Until) != len("15:04") {
+			return fmt.Errorf("timeWindow until must be HH:MM")
+		}
+		if cond.From == cond.Until {
+			return fmt.Errorf("timeWindow from and until must differ")
+		}
+		if _, err := cond.location(); err != nil {
+			return fmt.Errorf("unknown timezone %q", cond.Timezone)
+		}
+
+	case ResourceOwner, VerifiedEmail, Session2FA:
+
+	default:
+		return fmt.Errorf("unknown condition type %q", cond.Type)
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether the condition restricts the given permission.
+func (cond Condition) AppliesTo(permission string) bool {
+	return cond.Permission == "" || cond.Permission == permission
+}
+
+// Evaluate checks the condition against ctx. The reason explains a failure.
+func (cond Condition) Evaluate(ctx Context) (Result, string) {
+	switch cond.Type {
+	case MaxAmount:
+		if ctx.Amount == nil {
+			return Deferred, ""
+		}
+		total := *ctx.Amount
+		if cond.Period == PerDay {
+			if ctx.SpentToday == nil {
+				return Deferred, ""
+			}
+			total += *ctx.SpentToday
+		}
+		if total > cond.Limit {
+			return Fail, fmt.Sprintf("amount exceeds the %s limit of %d kobo", cond.Period, cond.Limit)
+		}
+
+	case TimeWindow:
+		loc, err := cond.location()
+		if err != nil {
+			return Fail, fmt.Sprintf("time window has an unknown timezone %q", cond.Timezone)
+		}
+		from, err := clockMinutes(cond.From)
+		if err != nil {
+			return Fail, fmt.Sprintf("time window has an invalid start %q", cond.From)
+		}
+		until, err := clockMinutes(cond.Until)
+		if err != nil {
+			return Fail, fmt.Sprintf("time window has an invalid end %q", cond.Until)
+		}
+		local := ctx.Now.In(loc)
+		now := local.Hour()*60 + local.Minute()
+		inside := from <= now && now < until
+		if from > until {
+			inside = now >= from || now < until
+		}
+		if !inside {
+			return Fail, fmt.Sprintf("only allowed between %s and %s (%s)", cond.From, cond.Until, loc)
+		}
+
+	case ResourceOwner:
+		if ctx.ResourceOwner == nil {
+			return Deferred, ""
+		}
+		if *ctx.ResourceOwner != ctx.SubjectID {
+			return Fail, "resource is not owned by the subject"
+		}
+
+	case VerifiedEmail:
+		if !ctx.EmailVerified {
+			return Fail, "requires a verified email address"
+		}
+
+	case Session2FA:
+		if ctx.Session2FA == nil {
+			return Deferred, ""
+		}
+		if !*ctx.Session2FA {
+			return Fail, "requires a session signed in with 2FA"
+		}
+
+	default:
+		return Fail, fmt.Sprintf("unknown condition type %q", cond.Type)
+	}
+
+	return Pass, ""
+}
+
+func (cond Condition) location() (*time.Location, error) {
+	if cond.Timezone == "" {
+		return time.LoadLocation(DefaultTimezone)
+	}
+	return time.LoadLocation(cond.Timezone)
+}
+
+// clockMinutes returns the minutes since midnight of an HH:MM time.
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeWindow(t *testing.T) {
+	lagos, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(clock string) time.Time {
+		parsed, err := time.ParseInLocation("15:04", clock, lagos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2024, time.March, 4, parsed.Hour(), parsed.Minute(), 0, 0, lagos)
+	}
+
+	tests := []struct {
+		name        string
+		from, until string
+		now         string
+		valid       bool
+		want        Result
+	}{
+		{"padded inside", "07:00", "17:00", "09:30", true, Pass},
+		{"padded at start", "07:00", "17:00", "07:00", true, Pass},
+		{"padded at end", "07:00", "17:00", "17:00", true, Fail},
+		{"padded before", "07:00", "17:00", "03:00", true, Fail},
+		{"unpadded inside", "7:00", "17:00", "09:30", false, Pass},
+		{"unpadded before", "7:00", "17:00", "03:00", false, Fail},
+		{"unpadded after", "7:00", "17:00", "18:00", false, Fail},
+		{"overnight late", "22:00", "06:00", "23:15", true, Pass},
+		{"overnight early", "22:00", "06:00", "03:00", true, Pass},
+		{"overnight daytime", "22:00", "06:00", "12:00", true, Fail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := Condition{Type: TimeWindow, From: tt.from, Until: tt.until}
+
+			if err := cond.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+			if got, reason := cond.Evaluate(Context{Now: at(tt.now)}); got != tt.want {
+				t.Errorf("Evaluate() at %s = %v (%s), want %v", tt.now, got, reason, tt.want)
+			}
+		})
+	}
+}
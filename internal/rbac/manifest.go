@@ -0,0 +1,126 @@
+package rbac
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed manifest.yaml
+var manifestYAML []byte
+
+type Manifest struct {
+	Version     int                  `yaml:"version"`
+	Permissions []ManifestPermission `yaml:"permissions"`
+	Roles       []ManifestRole       `yaml:"roles"`
+}
+
+type ManifestPermission struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	// MinTier is the KYC tier a user needs before the permission takes effect
+	MinTier string `yaml:"minTier"`
+}
+
+type ManifestRole struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Inherits    []string `yaml:"inherits"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// Load parses the embedded manifest and checks it against the Go constants.
+func Load() (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(manifestYAML, &m); err != nil {
+		return nil, fmt.Errorf("parse rbac manifest: %w", err)
+	}
+
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rbac manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+func (m *Manifest) validate() error {
+	if m.Version < 1 {
+		return fmt.Errorf("version must be a positive integer")
+	}
+
+	declared := make([]string, 0, len(m.Permissions))
+	for _, perm := range m.Permissions {
+		if slices.Contains(declared, perm.ID) {
+			return fmt.Errorf("permission %q declared twice", perm.ID)
+		}
+		declared = append(declared, perm.ID)
+
+		if _, err := ParseKYCTier(perm.MinTier); err != nil {
+			return fmt.Errorf("permission %q: %w", perm.ID, err)
+		}
+	}
+
+	// Constants and manifest must describe the same set of permissions
+	for _, perm := range AllPermissions {
+		if !slices.Contains(declared, string(perm)) {
+			return fmt.Errorf("permission constant %q is missing from the manifest", perm)
+		}
+	}
+	for _, id := range declared {
+		if !slices.Contains(AllPermissions, Permission(id)) {
+			return fmt.Errorf("permission %q has no Go constant", id)
+		}
+	}
+
+	var roles []string
+	for _, role := range m.Roles {
+		if slices.Contains(roles, role.ID) {
+			return fmt.Errorf("role %q declared twice", role.ID)
+		}
+		roles = append(roles, role.ID)
+
+		for _, perm := range role.Permissions {
+			if !slices.Contains(declared, perm) {
+				return fmt.Errorf("role %q references undeclared permission %q", role.ID, perm)
+			}
+		}
+	}
+
+	for _, role := range m.Roles {
+		for _, parent := range role.Inherits {
+			if !slices.Contains(roles, parent) {
+				return fmt.Errorf("role %q inherits from undeclared role %q", role.ID, parent)
+			}
+		}
+		if m.inheritsFrom(role.ID, role.ID, nil) {
+			return fmt.Errorf("role %q inherits from itself", role.ID)
+		}
+	}
+
+	for _, role := range []string{RolePlatformAdmin, RoleAdmin, RoleMember} {
+		if !slices.Contains(roles, role) {
+			return fmt.Errorf("role %q is missing from the manifest", role)
+		}
+	}
+
+	return nil
+}
+
+// inheritsFrom reports whether roleID has ancestor among its parents, directly
+// or through other roles. visited guards against cycles elsewhere in the graph.
+func (m *Manifest) inheritsFrom(roleID, ancestor string, visited []string) bool {
+	idx := slices.IndexFunc(m.Roles, func(r ManifestRole) bool { return r.ID == roleID })
+	if idx < 0 || slices.Contains(visited, roleID) {
+		return false
+	}
+	visited = append(visited, roleID)
+
+	for _, parent := range m.Roles[idx].Inherits {
+		if parent == ancestor || m.inheritsFrom(parent, ancestor, visited) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package rbac
+
+// Permission is the ID of a permission declared in manifest.yaml.
+// Handlers should check these constants rather than string literals.
+type Permission string
+
+// Auth service permissions
+const (
+	CanManageRoles Permission = "canManageRoles"
+	CanCreateUsers Permission = "canCreateUsers"
+	CanListUsers   Permission = "canListUsers"
+
+	CanAuthorizeSubjects   Permission = "canAuthorizeSubjects"
+	CanManageFamilyRoles   Permission = "canManageFamilyRoles"
+	CanDelegatePermissions Permission = "canDelegatePermissions"
+	CanViewIdentityNumbers Permission = "canViewIdentityNumbers"
+	CanReviewKYC           Permission = "canReviewKYC"
+	CanManageEmail         Permission = "canManageEmail"
+	CanViewProfileHistory  Permission = "canViewProfileHistory"
+)
+
+// Core service permissions
+const (
+	CanOperateFamilyAcct Permission = "CanOperateFamilyAcct"
+	CanCreateSubAcc      Permission = "CanCreateSubAcc"
+	CanDeleteSubAcc      Permission = "CanDeleteSubAcc"
+	CanEditFamilyAcc     Permission = "CanEditFamilyAcc"
+	CanSendtoSubAcc      Permission = "CanSendtoSubAcc"
+	CanSendtoBank        Permission = "CanSendtoBank"
+	CanFreezeSubAcc      Permission = "CanFreezeSubAcc"
+)
+
+// AllPermissions must list every constant above; Load fails if it drifts from the manifest.
+var AllPermissions = []Permission{
+	CanManageRoles,
+	CanCreateUsers,
+	CanListUsers,
+	CanAuthorizeSubjects,
+	CanManageFamilyRoles,
+	CanDelegatePermissions,
+	CanViewIdentityNumbers,
+	CanReviewKYC,
+	CanManageEmail,
+	CanViewProfileHistory,
+	CanOperateFamilyAcct,
+	CanCreateSubAcc,
+	CanDeleteSubAcc,
+	CanEditFamilyAcc,
+	CanSendtoSubAcc,
+	CanSendtoBank,
+	CanFreezeSubAcc,
+}
+
+// Roles assigned by the auth service itself
+const (
+	RolePlatformAdmin = "platformAdmin"
+	RoleAdmin         = "admin"
+	RoleMember        = "member"
+)
+
+// FamilyAssignableRoles are the global roles a family admin may give members,
+// alongside their family's own roles.
+var FamilyAssignableRoles = []string{RoleAdmin, RoleMember}
@@ -0,0 +1,38 @@
+package rbac
+
+import "fmt"
+
+// KYCTier is how far a user has got through identity verification. Higher
+// tiers unlock permissions that declare a minTier in the manifest.
+type KYCTier int
+
+const (
+	TierNone KYCTier = iota
+	TierEmailVerified
+	TierPhoneVerified
+	TierNINVerified
+	TierBVNVerified
+)
+
+var tierNames = []string{"none", "emailVerified", "phoneVerified", "ninVerified", "bvnVerified"}
+
+func (t KYCTier) String() string {
+	if t < TierNone || int(t) >= len(tierNames) {
+		return fmt.Sprintf("KYCTier(%d)", int(t))
+	}
+	return tierNames[t]
+}
+
+// ParseKYCTier reads a tier name. The empty string is TierNone, so
+// permissions without a minTier are open to every user.
+func ParseKYCTier(name string) (KYCTier, error) {
+	if name == "" {
+		return TierNone, nil
+	}
+	for i, tierName := range tierNames {
+		if tierName == name {
+			return KYCTier(i), nil
+		}
+	}
+	return TierNone, fmt.Errorf("unknown KYC tier %q", name)
+}
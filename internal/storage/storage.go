@@ -0,0 +1,102 @@
+// Package storage keeps uploaded files in the backend chosen by STORAGE: a
+// local directory, or an S3-compatible bucket such as AWS S3 or MinIO.
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// NewStore builds the store named by STORAGE: "local" (the default) to keep
+// files under STORAGE_DIR, or "s3" to keep them in the S3_BUCKET bucket.
+func NewStore() (interfaces.BlobStore, error) {
+	switch backend := os.Getenv("STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "images"
+		}
+		return NewLocalStore(dir)
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := os.Getenv("S3_ENDPOINT")
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+		}
+		return NewS3Store(&S3Store{
+			Endpoint:        endpoint,
+			Region:          region,
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+			Client:          &http.Client{Timeout: 30 * time.Second},
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE %q, want local or s3", backend)
+	}
+}
+
+// ProfilePicturePrefix is the key prefix of stored profile pictures.
+const ProfilePicturePrefix = "profilePics/"
+
+// KYCDocumentPrefix is the key prefix of ID documents uploaded for review.
+// No route serves it directly; reviewers fetch documents one at a time.
+const KYCDocumentPrefix = "kycDocuments/"
+
+// profilePicturePath is where the API serves profile pictures, relative to
+// /api/v1/.
+const profilePicturePath = "images/profile-pic/"
+
+// ProfilePictureURL is the URL a profile records for the picture stored
+// under key.
+func ProfilePictureURL(key string) string {
+	return profilePicturePath + strings.TrimPrefix(key, ProfilePicturePrefix)
+}
+
+// ProfilePictureKey is the key of the picture a profile's URL points at. It
+// reports false for URLs that don't point at a stored picture.
+func ProfilePictureKey(url string) (string, bool) {
+	name, ok := strings.CutPrefix(url, profilePicturePath)
+	if !ok || !validName(name) {
+		return "", false
+	}
+	return ProfilePicturePrefix + name, true
+}
+
+// ThumbnailKey is the key of a picture's thumbnail of the given size, e.g.
+// "profilePics/<uuid>_128.jpg" for "profilePics/<uuid>.jpg".
+func ThumbnailKey(key string, size int) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_" + strconv.Itoa(size) + ext
+}
+
+// PictureKey is the key of the picture a thumbnail's key belongs to, or key
+// itself if it isn't a thumbnail's.
+func PictureKey(key string) string {
+	ext := path.Ext(key)
+	base := strings.TrimSuffix(key, ext)
+	i := strings.LastIndex(base, "_")
+	if i < 0 {
+		return key
+	}
+	if _, err := strconv.Atoi(base[i+1:]); err != nil {
+		return key
+	}
+	return base[:i] + ext
+}
+
+// validName reports whether name can be used as one segment of a key.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
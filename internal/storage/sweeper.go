@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/InternPulse/famtrust-backend-auth/internal/interfaces"
+)
+
+// Sweeper deletes stored profile pictures, and their thumbnails, that no
+// profile points at, such as those of deleted users or left behind by a
+// failed upload.
+type Sweeper struct {
+	Models interfaces.Models
+	Store  interfaces.BlobStore
+
+	// Interval is how often the store is swept
+	Interval time.Duration
+	// MinAge keeps pictures uploaded more recently than this, whose profile
+	// may not be saved yet
+	MinAge time.Duration
+}
+
+// NewSweeper returns a sweeper that runs daily.
+func NewSweeper(models interfaces.Models, store interfaces.BlobStore) *Sweeper {
+	return &Sweeper{
+		Models:   models,
+		Store:    store,
+		Interval: 24 * time.Hour,
+		MinAge:   time.Hour,
+	}
+}
+
+// Run sweeps the store every Interval until ctx is done.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.Sweep(ctx); err != nil {
+			log.Printf("Failed to sweep profile pictures: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d unused profile pictures", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes unused profile pictures older than MinAge, returning how
+// many it deleted.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	// List before reading the profiles, so a picture saved in between is
+	// either too new to delete or already referenced
+	blobs, err := s.Store.List(ctx, ProfilePicturePrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	urls, err := s.Models.Users().Unscoped().GetProfilePictureUrls()
+	if err != nil {
+		return 0, err
+	}
+	inUse := map[string]bool{}
+	for _, url := range urls {
+		if key, ok := ProfilePictureKey(url); ok {
+			inUse[key] = true
+		}
+	}
+
+	cutoff := time.Now().Add(-s.MinAge)
+	deleted := 0
+	for _, blob := range blobs {
+		// Pictures uploaded before thumbnails can have names like thumbnails'
+		if inUse[blob.Key] || inUse[PictureKey(blob.Key)] || blob.ModTime.After(cutoff) {
+			continue
+		}
+		if err := s.Store.Delete(ctx, blob.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}